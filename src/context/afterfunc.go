@@ -0,0 +1,60 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import "sync"
+
+// AfterFunc arranges to call f in its own goroutine after ctx is done
+// (canceled or timed out). If ctx is already done, AfterFunc calls f
+// immediately in its own goroutine.
+//
+// Multiple calls to AfterFunc on a context operate independently; one
+// does not replace another.
+//
+// Calling the returned stop function stops the association of ctx with
+// f. It returns true if the call stopped f from being run. If stop
+// returns false, either the context is done and f has been started in
+// its own goroutine, or f was already stopped. The stop function does
+// not wait for f to complete before returning. If the caller needs to
+// know whether f is completed, it must coordinate with f explicitly.
+//
+// Like a child context, AfterFunc hooks directly into a parent created
+// by this package, so it does not start a goroutine to wait for ctx to
+// be done unless ctx is of another type.
+func AfterFunc(ctx Context, f func()) (stop func() bool) {
+	a := &afterFuncCtx{
+		cancelCtx: newCancelCtx(ctx),
+		f:         f,
+	}
+	propagateCancel(ctx, a)
+	return func() bool {
+		stopped := false
+		a.once.Do(func() {
+			stopped = true
+		})
+		if stopped {
+			a.cancel(true, Canceled, nil)
+		}
+		return stopped
+	}
+}
+
+// An afterFuncCtx is the child through which AfterFunc learns that its
+// context is done. It is never returned to callers.
+type afterFuncCtx struct {
+	cancelCtx
+	once sync.Once // either starts running f or stops f from running
+	f    func()
+}
+
+func (a *afterFuncCtx) cancel(removeFromParent bool, err, cause error) {
+	a.cancelCtx.cancel(false, err, cause)
+	if removeFromParent {
+		removeChild(a.Context, a)
+	}
+	a.once.Do(func() {
+		go a.f()
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// All returns a Context that is done only once every one of contexts is
+// done. Its Err is the Err of the last of contexts to be done. Its
+// deadline is the latest of their deadlines, or none if any of them has
+// no deadline, and Value looks up a key in each of contexts in turn.
+//
+// The returned Context's Done channel is also closed when the returned
+// cancel function is called, which stops watching contexts. Code should
+// call cancel as soon as the returned Context is no longer needed.
+//
+// All panics if contexts is empty.
+func All(contexts ...Context) (Context, CancelFunc) {
+	if len(contexts) == 0 {
+		panic("context: All with no contexts")
+	}
+	for _, ctx := range contexts {
+		if ctx == nil {
+			panic("cannot create context from nil parent")
+		}
+	}
+	c := &allCtx{
+		cancelCtx: newCancelCtx(Background()),
+		contexts:  contexts,
+	}
+	for _, ctx := range contexts {
+		if ctx.Done() == nil {
+			// ctx is never done, so neither is c until canceled.
+			return c, func() { c.cancelFrom(CanceledBySelf, false, Canceled, nil) }
+		}
+	}
+	var remaining atomic.Int32
+	remaining.Store(int32(len(contexts)))
+	for _, ctx := range contexts {
+		ctx := ctx
+		atomic.AddInt32(&goroutines, +1)
+		go func() {
+			select {
+			case <-ctx.Done():
+				if remaining.Add(-1) == 0 {
+					c.cancel(false, ctx.Err(), Cause(ctx))
+				}
+			case <-c.Done():
+			}
+		}()
+	}
+	return c, func() { c.cancelFrom(CanceledBySelf, false, Canceled, nil) }
+}
+
+// An allCtx is done when all of its contexts are done.
+type allCtx struct {
+	cancelCtx
+	contexts []Context
+}
+
+func (c *allCtx) Deadline() (deadline time.Time, ok bool) {
+	for _, ctx := range c.contexts {
+		d, ok := ctx.Deadline()
+		if !ok {
+			return time.Time{}, false
+		}
+		if d.After(deadline) {
+			deadline = d
+		}
+	}
+	return deadline, true
+}
+
+func (c *allCtx) Value(key any) any {
+	if key == &cancelCtxKey {
+		return &c.cancelCtx
+	}
+	for _, ctx := range c.contexts {
+		if v := ctx.Value(key); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func (c *allCtx) String() string {
+	s := "context.All("
+	for i, ctx := range c.contexts {
+		if i > 0 {
+			s += ", "
+		}
+		s += contextName(ctx)
+	}
+	return s + ")"
+}
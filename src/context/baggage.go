@@ -0,0 +1,94 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+// A Baggage is an immutable set of string key-value pairs that
+// travels with a Context, such as request metadata to be propagated
+// to downstream services.
+//
+// The zero Baggage is empty and ready for use.
+type Baggage struct {
+	m map[string]string
+}
+
+// NewBaggage returns a Baggage holding a copy of the pairs in m.
+func NewBaggage(m map[string]string) Baggage {
+	if len(m) == 0 {
+		return Baggage{}
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return Baggage{m: c}
+}
+
+// Get returns the value associated with key and whether it is present.
+func (b Baggage) Get(key string) (string, bool) {
+	v, ok := b.m[key]
+	return v, ok
+}
+
+// Len returns the number of pairs in b.
+func (b Baggage) Len() int {
+	return len(b.m)
+}
+
+// Range calls f sequentially for each key and value in b, in
+// unspecified order. If f returns false, Range stops the iteration.
+func (b Baggage) Range(f func(key, value string) bool) {
+	for k, v := range b.m {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// String returns a description of b for debugging, listing the
+// pairs in unspecified order.
+func (b Baggage) String() string {
+	s := "Baggage{"
+	first := true
+	for k, v := range b.m {
+		if !first {
+			s += ", "
+		}
+		first = false
+		s += k + ": " + v
+	}
+	return s + "}"
+}
+
+// &baggageKey is the key under which a Context stores its Baggage.
+var baggageKey int
+
+// WithBaggage returns a copy of parent carrying the baggage of parent
+// merged with b. Where both have a value for the same key, the value
+// from b is used. The merged baggage is stored as a single value, so
+// BaggageFrom finds all of it with one lookup however many times
+// WithBaggage was applied.
+func WithBaggage(parent Context, b Baggage) Context {
+	if parent == nil {
+		panic("cannot create context from nil parent")
+	}
+	if pb := BaggageFrom(parent); pb.Len() > 0 {
+		m := make(map[string]string, pb.Len()+b.Len())
+		for k, v := range pb.m {
+			m[k] = v
+		}
+		for k, v := range b.m {
+			m[k] = v
+		}
+		b = Baggage{m: m}
+	}
+	return &valueCtx{parent, &baggageKey, b}
+}
+
+// BaggageFrom returns the baggage carried by ctx,
+// or an empty Baggage if there is none.
+func BaggageFrom(ctx Context) Baggage {
+	b, _ := ctx.Value(&baggageKey).(Baggage)
+	return b
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import "time"
+
+// SplitBudget divides the time remaining until parent's deadline into n
+// equal shares for n operations run one after another. It returns n
+// Contexts derived from parent and a CancelFunc that cancels all of them.
+//
+// The i'th Context (counting from zero) has a deadline i+1 shares from
+// now, so the first operation is bounded by its own share and time left
+// unused by earlier operations carries over to later ones. The last
+// Context has parent's deadline. If parent has no deadline, the returned
+// Contexts have none either.
+//
+// SplitBudget panics if n is not positive.
+//
+// Canceling the returned Contexts releases resources associated with
+// them, so code should call cancel as soon as the operations they are
+// used for complete.
+func SplitBudget(parent Context, n int) (ctxs []Context, cancel CancelFunc) {
+	if n <= 0 {
+		panic("context: SplitBudget with non-positive n")
+	}
+	ctxs = make([]Context, n)
+	cancels := make([]CancelFunc, n)
+	deadline, ok := parent.Deadline()
+	now := time.Now()
+	share := deadline.Sub(now) / time.Duration(n)
+	for i := range ctxs {
+		if !ok {
+			ctxs[i], cancels[i] = WithCancel(parent)
+			continue
+		}
+		d := deadline
+		if i < n-1 {
+			d = now.Add(share * time.Duration(i+1))
+		}
+		ctxs[i], cancels[i] = WithDeadline(parent, d)
+	}
+	return ctxs, func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+}
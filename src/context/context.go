@@ -71,6 +71,7 @@ package context
 
 import (
 	"errors"
+	"std/internal/itoa"
 	"std/internal/reflectlite"
 	"sync"
 	"sync/atomic"
@@ -261,7 +262,57 @@ func WithCancel(parent Context) (ctx Context, cancel CancelFunc) {
 	}
 	c := newCancelCtx(parent)
 	propagateCancel(parent, &c)
-	return &c, func() { c.cancel(true, Canceled) }
+	return &c, func() { c.cancel(true, Canceled, nil) }
+}
+
+// A CancelCauseFunc behaves like a CancelFunc but additionally sets the
+// cancellation cause. This cause can be retrieved by calling Cause on
+// the canceled Context or on any of its derived Contexts.
+//
+// If the context has already been canceled, CancelCauseFunc does not
+// set the cause. For example, if childContext is derived from
+// parentContext:
+//   - if parentContext is canceled with cause1 before childContext is
+//     canceled with cause2, then Cause(parentContext) ==
+//     Cause(childContext) == cause1
+//   - if childContext is canceled with cause2 before parentContext is
+//     canceled with cause1, then Cause(parentContext) == cause1 and
+//     Cause(childContext) == cause2
+type CancelCauseFunc func(cause error)
+
+// WithCancelCause behaves like WithCancel but returns a CancelCauseFunc
+// instead of a CancelFunc. Calling cancel with a non-nil error (the
+// "cause") records that error in ctx; it can then be retrieved using
+// Cause(ctx). Calling cancel with nil sets the cause to Canceled. The
+// Err method of ctx still returns Canceled.
+//
+// Example use:
+//
+//	ctx, cancel := context.WithCancelCause(parent)
+//	cancel(myError)
+//	ctx.Err() // returns context.Canceled
+//	context.Cause(ctx) // returns myError
+func WithCancelCause(parent Context) (ctx Context, cancel CancelCauseFunc) {
+	if parent == nil {
+		panic("cannot create context from nil parent")
+	}
+	c := newCancelCtx(parent)
+	propagateCancel(parent, &c)
+	return &c, func(cause error) { c.cancel(true, Canceled, cause) }
+}
+
+// Cause returns a non-nil error explaining why c was canceled. The first
+// cancellation of c or one of its parents sets the cause. If that
+// cancellation happened via a call to CancelCauseFunc(err), then Cause
+// returns err. Otherwise Cause(c) returns the same value as c.Err().
+// Cause returns nil if c has not been canceled yet.
+func Cause(c Context) error {
+	if cc, ok := c.Value(&cancelCtxKey).(*cancelCtx); ok {
+		cc.mu.Lock()
+		defer cc.mu.Unlock()
+		return cc.cause
+	}
+	return c.Err()
 }
 
 // newCancelCtx returns an initialized cancelCtx.
@@ -283,7 +334,7 @@ func propagateCancel(parent Context, child canceler) {
 	select {
 	case <-done: // note 父取消，子也要取消
 		// parent is already canceled
-		child.cancel(false, parent.Err())
+		child.cancel(false, parent.Err(), Cause(parent))
 		return
 	default:
 	}
@@ -292,7 +343,7 @@ func propagateCancel(parent Context, child canceler) {
 		p.mu.Lock()
 		if p.err != nil {
 			// parent has already been canceled
-			child.cancel(false, p.err)
+			child.cancel(false, p.err, p.cause)
 		} else {
 			if p.children == nil {
 				p.children = make(map[canceler]struct{})
@@ -305,7 +356,7 @@ func propagateCancel(parent Context, child canceler) {
 		go func() { // note 父还没取消，则新建一个协程，等待父取消后把子也取消了，或者是子自己取消了
 			select {
 			case <-parent.Done():
-				child.cancel(false, parent.Err())
+				child.cancel(false, parent.Err(), Cause(parent))
 			case <-child.Done():
 			}
 		}()
@@ -357,7 +408,7 @@ func removeChild(parent Context, child canceler) {
 // A canceler is a context type that can be canceled directly. The
 // implementations are *cancelCtx and *timerCtx.
 type canceler interface {
-	cancel(removeFromParent bool, err error)
+	cancel(removeFromParent bool, err, cause error)
 	Done() <-chan struct{}
 }
 
@@ -378,6 +429,8 @@ type cancelCtx struct {
 	done     atomic.Value          // of chan struct{}, created lazily, closed by first cancel call
 	children map[canceler]struct{} // set to nil by the first cancel call
 	err      error                 // set to non-nil by the first cancel call
+	cause    error                 // set to non-nil by the first cancel call
+	source   CancelSource          // set by the first cancel call
 }
 
 func (c *cancelCtx) Value(key any) any {
@@ -427,17 +480,29 @@ func (c *cancelCtx) String() string {
 
 // cancel closes c.done, cancels each of c's children, and, if
 // removeFromParent is true, removes c from its parent's children.
+// cancel sets c.cause to cause if this is the first time c is canceled.
 // note 关闭c.done，并调用每个children的cancels()，同时将c从它的parent的children中移除
-func (c *cancelCtx) cancel(removeFromParent bool, err error) {
+func (c *cancelCtx) cancel(removeFromParent bool, err, cause error) {
+	c.cancelFrom(cancelSourceOf(removeFromParent, err), removeFromParent, err, cause)
+}
+
+// cancelFrom is like cancel but records src as the source of the
+// cancellation rather than deriving it from removeFromParent.
+func (c *cancelCtx) cancelFrom(src CancelSource, removeFromParent bool, err, cause error) {
 	if err == nil {
 		panic("context: internal error: missing cancel error")
 	}
+	if cause == nil {
+		cause = err
+	}
 	c.mu.Lock()
 	if c.err != nil {
 		c.mu.Unlock()
 		return // already canceled
 	}
 	c.err = err
+	c.cause = cause
+	c.source = src
 	d, _ := c.done.Load().(chan struct{})
 	if d == nil {
 		c.done.Store(closedchan)
@@ -446,7 +511,7 @@ func (c *cancelCtx) cancel(removeFromParent bool, err error) {
 	}
 	for child := range c.children {
 		// NOTE: acquiring the child's lock while holding parent's lock.
-		child.cancel(false, err)
+		child.cancel(false, err, cause)
 	}
 	c.children = nil
 	c.mu.Unlock()
@@ -484,17 +549,18 @@ func WithDeadline(parent Context, d time.Time) (Context, CancelFunc) {
 	propagateCancel(parent, c)
 	dur := time.Until(d)
 	if dur <= 0 { // todo ddl已经到了，直接调用cancel；怎么还返回cancel了...不懂
-		c.cancel(true, DeadlineExceeded) // deadline has already passed
-		return c, func() { c.cancel(false, Canceled) }
+		c.cancel(true, DeadlineExceeded, nil) // deadline has already passed
+		return c, func() { c.cancel(false, Canceled, nil) }
 	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.err == nil {
 		c.timer = time.AfterFunc(dur, func() { // note 直接上定时器，到期自己调用cancel
-			c.cancel(true, DeadlineExceeded)
+			c.cancel(true, DeadlineExceeded, nil)
 		})
+		registerDeadline(c)
 	}
-	return c, func() { c.cancel(true, Canceled) }
+	return c, func() { c.cancel(true, Canceled, nil) }
 }
 
 // A timerCtx carries a timer and a deadline. It embeds a cancelCtx to
@@ -517,8 +583,8 @@ func (c *timerCtx) String() string {
 		time.Until(c.deadline).String() + "])"
 }
 
-func (c *timerCtx) cancel(removeFromParent bool, err error) {
-	c.cancelCtx.cancel(false, err)
+func (c *timerCtx) cancel(removeFromParent bool, err, cause error) {
+	c.cancelCtx.cancelFrom(cancelSourceOf(removeFromParent, err), false, err, cause)
 	if removeFromParent {
 		// Remove this timerCtx from its parent cancelCtx's children.
 		removeChild(c.cancelCtx.Context, c)
@@ -529,12 +595,190 @@ func (c *timerCtx) cancel(removeFromParent bool, err error) {
 		c.timer = nil
 	}
 	c.mu.Unlock()
+	unregisterDeadline(c)
+}
+
+// A CancelSource describes what canceled a context.
+type CancelSource int
+
+const (
+	NotCanceled        CancelSource = iota // the context has not been canceled
+	CanceledBySelf                         // the context's own CancelFunc was called
+	CanceledByParent                       // the cancellation propagated from a parent
+	CanceledByDeadline                     // the context's own deadline expired
+)
+
+// cancelSourceOf returns the source of a cancel call on a context
+// created by this package. Only the context's own CancelFunc and
+// timer remove it from its parent; cancellations propagated from
+// the parent do not.
+func cancelSourceOf(removeFromParent bool, err error) CancelSource {
+	switch {
+	case !removeFromParent:
+		return CanceledByParent
+	case err == DeadlineExceeded:
+		return CanceledByDeadline
+	default:
+		return CanceledBySelf
+	}
+}
+
+// CancelSourceOf reports what canceled ctx. For a context derived by
+// WithCancel, WithDeadline or WithTimeout (possibly wrapped by
+// WithValue), it reports whether the context's own CancelFunc was
+// called, its own deadline expired, or the cancellation came from a
+// parent, including a parent whose deadline expired.
+//
+// For other contexts the source is not known, and CancelSourceOf
+// reports CanceledByDeadline if ctx.Err() is DeadlineExceeded and
+// CanceledBySelf for any other non-nil error.
+func CancelSourceOf(ctx Context) CancelSource {
+	if p, ok := ctx.Value(&cancelCtxKey).(*cancelCtx); ok && p.Done() == ctx.Done() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.source
+	}
+	switch ctx.Err() {
+	case nil:
+		return NotCanceled
+	case DeadlineExceeded:
+		return CanceledByDeadline
+	default:
+		return CanceledBySelf
+	}
+}
+
+// CauseChain returns the causes with which ctx and the contexts it was
+// derived from were canceled, innermost first, each as Cause would
+// report it. It follows the chain of contexts created by WithCancel,
+// WithCancelCause, WithDeadline and WithTimeout (possibly wrapped by
+// WithValue) and stops at the first one that has not been canceled or
+// whose parent is not such a context. Used together with
+// CancelSourceOf on each level, it shows how a cancellation reached a
+// deeply derived context.
+//
+// A context canceled by its parent inherits the parent's cause, so
+// consecutive causes in the chain are often the same; a cause differs
+// from the one after it where a context was canceled before its
+// parent, by its own CancelFunc, CancelCauseFunc or deadline.
+func CauseChain(ctx Context) []error {
+	var chain []error
+	for {
+		p, ok := ctx.Value(&cancelCtxKey).(*cancelCtx)
+		if !ok || p.Done() != ctx.Done() {
+			return chain
+		}
+		p.mu.Lock()
+		cause := p.cause
+		p.mu.Unlock()
+		if cause == nil {
+			return chain
+		}
+		chain = append(chain, cause)
+		ctx = p.Context
+	}
+}
+
+// DeadlineOwner returns the context that imposes ctx's deadline: the
+// innermost of ctx and the contexts it was derived from whose own
+// deadline, rather than one inherited from its parent, is ctx's
+// deadline. For a context created by WithDeadline or WithTimeout, the
+// String method of the result names the call, such as
+// "context.Background.WithDeadline(...)", which helps tell apart nested
+// deadlines. DeadlineOwner reports false if ctx has no deadline.
+//
+// DeadlineOwner sees through the contexts created by this package. A
+// context of another type that has a deadline is taken to impose it.
+func DeadlineOwner(ctx Context) (Context, bool) {
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, false
+	}
+	for {
+		var own time.Time
+		var parent Context
+		switch c := ctx.(type) {
+		case *valueCtx:
+			ctx = c.Context
+			continue
+		case *mapValueCtx:
+			ctx = c.Context
+			continue
+		case *valuesCtx:
+			ctx = c.Context
+			continue
+		case *cancelCtx:
+			ctx = c.Context
+			continue
+		case *timerCtx:
+			own, parent = c.deadline, c.cancelCtx.Context
+		case *idleCtx:
+			c.mu.Lock()
+			own, parent = c.deadline, c.cancelCtx.Context
+			c.mu.Unlock()
+		default:
+			_, ok := ctx.Deadline()
+			if !ok {
+				return nil, false
+			}
+			return ctx, true
+		}
+		if d, ok := parent.Deadline(); !ok || !d.Before(own) {
+			return ctx, true
+		}
+		ctx = parent
+	}
+}
+
+// CloseOnCancel arranges for ch to be closed when ctx is done, for
+// passing ctx's cancellation to code that waits for a channel to be
+// closed. The returned stop function undoes the arrangement: once stop
+// returns, ch has either already been closed or will not be closed by
+// CloseOnCancel. Calling stop releases the resources associated with
+// the arrangement, so code should call it once ch is no longer needed.
+// Calling stop more than once has no effect.
+//
+// The caller must not close ch itself unless stop has returned first.
+// If ctx can never be done, ch is never closed.
+func CloseOnCancel(ctx Context, ch chan<- struct{}) (stop func()) {
+	done := ctx.Done()
+	if done == nil {
+		return func() {}
+	}
+	stopc := make(chan struct{})
+	exited := make(chan struct{})
+	atomic.AddInt32(&goroutines, +1)
+	go func() {
+		defer close(exited)
+		select {
+		case <-done:
+			close(ch)
+		case <-stopc:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stopc) })
+		<-exited
+	}
+}
+
+// ActiveDeadlines returns a snapshot of the deadlines of all contexts
+// created by WithDeadline or WithTimeout that have neither been canceled
+// nor reached their deadline, in no particular order. It is intended for
+// debugging, for example to see why requests are timing out.
+//
+// Deadlines are only tracked in programs built with the contextdebug
+// build tag. Otherwise ActiveDeadlines always returns nil.
+func ActiveDeadlines() []time.Time {
+	return activeDeadlines()
 }
 
 // WithTimeout returns WithDeadline(parent, time.Now().Add(timeout)).
 //
 // Canceling this context releases resources associated with it, so code should
-// call cancel as soon as the operations running in this Context complete:
+// call cancel as soon as the operations running in this Context complete.
+// Since cancel also cancels the context early, there is no need to wrap
+// a WithCancel context to stop the operations before the timeout:
 //
 //	func slowOperationWithTimeout(ctx context.Context) (Result, error) {
 //		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
@@ -545,6 +789,28 @@ func WithTimeout(parent Context, timeout time.Duration) (Context, CancelFunc) {
 	return WithDeadline(parent, time.Now().Add(timeout))
 }
 
+// ErrChan returns a channel that receives ctx.Err() once ctx is done and is
+// then closed. The channel is buffered, so the error is delivered even if
+// nobody is receiving at the time ctx is done.
+//
+// If ctx can never be canceled (its Done method returns nil), nothing is ever
+// sent on the returned channel. Otherwise ErrChan starts a goroutine that
+// exits when ctx is done, so callers should make sure ctx is eventually
+// canceled.
+func ErrChan(ctx Context) <-chan error {
+	errc := make(chan error, 1)
+	done := ctx.Done()
+	if done == nil {
+		return errc
+	}
+	go func() {
+		<-done
+		errc <- ctx.Err()
+		close(errc)
+	}()
+	return errc
+}
+
 // WithValue returns a copy of parent in which the value associated with key is
 // val.
 //
@@ -586,7 +852,7 @@ type valueCtx struct {
 
 // stringify tries a bit to stringify v, without using fmt, since we don't
 // want context depending on the unicode tables. This is only used by
-// *valueCtx.String().
+// *valueCtx.String() and *valuesCtx.String().
 func stringify(v any) string {
 	switch s := v.(type) {
 	case stringer:
@@ -618,6 +884,16 @@ func value(c Context, key any) any {
 				return ctx.val
 			}
 			c = ctx.Context
+		case *mapValueCtx:
+			if v, ok := ctx.lookup(key); ok {
+				return v
+			}
+			c = ctx.Context
+		case *valuesCtx:
+			if v, ok := ctx.lookup(key); ok {
+				return v
+			}
+			c = ctx.Context
 		case *cancelCtx:
 			if key == &cancelCtxKey {
 				return c
@@ -635,3 +911,160 @@ func value(c Context, key any) any {
 		}
 	}
 }
+
+// MustValue returns the value associated with key in ctx, asserted to
+// type T. It is for values that a caller requires, such as a request ID
+// injected by middleware. MustValue panics with a message naming the
+// type of key if ctx has no value for key or if the value is not a T,
+// and if key is nil.
+func MustValue[T any](ctx Context, key any) T {
+	if key == nil {
+		panic("context: MustValue with nil key")
+	}
+	v := ctx.Value(key)
+	if t, ok := v.(T); ok {
+		return t
+	}
+	want := reflectlite.TypeOf((*T)(nil)).Elem().String()
+	if v == nil {
+		panic("context: no value for key of type " + reflectlite.TypeOf(key).String() +
+			", want " + want)
+	}
+	panic("context: value for key of type " + reflectlite.TypeOf(key).String() +
+		" has type " + reflectlite.TypeOf(v).String() + ", want " + want)
+}
+
+// InjectValues returns a copy of parent in which each key in values is
+// associated with its value, as if by calling WithValue for each pair,
+// but storing all of them in a single node. If parent was itself
+// returned by InjectValues, the pairs are merged with the parent's into
+// a single node, with values taking precedence, so that a chain of
+// middleware each injecting a few values keeps the context shallow.
+//
+// InjectValues copies values. Like WithValue, it panics if a key is nil.
+// Keys are necessarily comparable, since they are map keys.
+func InjectValues(parent Context, values map[any]any) Context {
+	if parent == nil {
+		panic("cannot create context from nil parent")
+	}
+	for key := range values {
+		if key == nil {
+			panic("nil key")
+		}
+	}
+	if len(values) == 0 {
+		return parent
+	}
+	var vals map[any]any
+	if p, ok := parent.(*mapValueCtx); ok {
+		// Merging is safe because a mapValueCtx only adds values
+		// and otherwise behaves exactly like its parent.
+		parent = p.Context
+		vals = make(map[any]any, len(p.vals)+len(values))
+		for k, v := range p.vals {
+			vals[k] = v
+		}
+	} else {
+		vals = make(map[any]any, len(values))
+	}
+	for k, v := range values {
+		vals[k] = v
+	}
+	return &mapValueCtx{parent, vals}
+}
+
+// A mapValueCtx carries a set of key-value pairs. It implements Value
+// for those keys and delegates all other calls to the embedded Context.
+type mapValueCtx struct {
+	Context
+	vals map[any]any
+}
+
+// lookup returns the value associated with key in c, if any.
+func (c *mapValueCtx) lookup(key any) (any, bool) {
+	if key != nil && !reflectlite.TypeOf(key).Comparable() {
+		// A map lookup would panic; no such key can be in vals.
+		return nil, false
+	}
+	v, ok := c.vals[key]
+	return v, ok
+}
+
+func (c *mapValueCtx) String() string {
+	return contextName(c.Context) + ".InjectValues(" + itoa.Itoa(len(c.vals)) + " values)"
+}
+
+func (c *mapValueCtx) Value(key any) any {
+	if v, ok := c.lookup(key); ok {
+		return v
+	}
+	return value(c.Context, key)
+}
+
+// WithValues returns a copy of parent in which each key in kvs, a list
+// of alternating keys and values, is associated with the value that
+// follows it, as if by calling WithValue for each pair in turn, but
+// storing all of them in a single node. Attaching several values at once
+// this way makes fewer allocations and keeps the context shallower than
+// a chain of WithValue calls.
+//
+// WithValues panics if kvs has an odd number of elements, and, like
+// WithValue, if a key is nil or not comparable.
+func WithValues(parent Context, kvs ...any) Context {
+	if parent == nil {
+		panic("cannot create context from nil parent")
+	}
+	if len(kvs)%2 != 0 {
+		panic("odd number of arguments to WithValues")
+	}
+	for i := 0; i < len(kvs); i += 2 {
+		if kvs[i] == nil {
+			panic("nil key")
+		}
+		if !reflectlite.TypeOf(kvs[i]).Comparable() {
+			panic("key is not comparable")
+		}
+	}
+	if len(kvs) == 0 {
+		return parent
+	}
+	return &valuesCtx{parent, append([]any(nil), kvs...)}
+}
+
+// A valuesCtx carries a list of key-value pairs, stored alternately in
+// kvs. It implements Value for those keys and delegates all other calls
+// to the embedded Context.
+type valuesCtx struct {
+	Context
+	kvs []any
+}
+
+// lookup returns the value associated with key in c, if any. Later
+// pairs take precedence, as they would in a chain of WithValue calls.
+func (c *valuesCtx) lookup(key any) (any, bool) {
+	for i := len(c.kvs) - 2; i >= 0; i -= 2 {
+		if c.kvs[i] == key {
+			return c.kvs[i+1], true
+		}
+	}
+	return nil, false
+}
+
+func (c *valuesCtx) String() string {
+	s := contextName(c.Context) + ".WithValues("
+	for i := 0; i < len(c.kvs); i += 2 {
+		if i > 0 {
+			s += ", "
+		}
+		s += "type " + reflectlite.TypeOf(c.kvs[i]).String() +
+			", val " + stringify(c.kvs[i+1])
+	}
+	return s + ")"
+}
+
+func (c *valuesCtx) Value(key any) any {
+	if v, ok := c.lookup(key); ok {
+		return v
+	}
+	return value(c.Context, key)
+}
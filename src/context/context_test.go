@@ -5,6 +5,7 @@
 package context
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"runtime"
@@ -421,6 +422,15 @@ func XTestAllocs(t testingT, testingShort func() bool, testingAllocsPerRun func(
 			limit:      3,
 			gccgoLimit: 3,
 		},
+		{
+			desc: fmt.Sprintf("WithValues(bg, %v, nil, %v, nil)", k1, k2),
+			f: func() {
+				c := WithValues(bg, k1, nil, k2, nil)
+				c.Value(k1)
+			},
+			limit:      3,
+			gccgoLimit: 3,
+		},
 		{
 			desc: "WithTimeout(bg, 1*time.Nanosecond)",
 			f: func() {
@@ -786,3 +796,906 @@ func XTestCustomContextGoroutines(t testingT) {
 	defer cancel7()
 	checkNoGoroutine()
 }
+
+func XTestErrChan(t testingT) {
+	ctx, cancel := WithCancel(Background())
+	errc := ErrChan(ctx)
+	select {
+	case err := <-errc:
+		t.Fatalf("ErrChan received %v before cancel", err)
+	default:
+	}
+	cancel()
+	select {
+	case err := <-errc:
+		if err != Canceled {
+			t.Errorf("ErrChan received %v, want %v", err, Canceled)
+		}
+	case <-time.After(veryLongDuration):
+		t.Fatal("ErrChan did not receive after cancel")
+	}
+	if err, ok := <-errc; ok {
+		t.Errorf("ErrChan received %v after first error, want closed", err)
+	}
+
+	ctx, cancel = WithTimeout(Background(), shortDuration)
+	defer cancel()
+	if err := <-ErrChan(ctx); err != DeadlineExceeded {
+		t.Errorf("ErrChan received %v, want %v", err, DeadlineExceeded)
+	}
+
+	select {
+	case err := <-ErrChan(Background()):
+		t.Errorf("ErrChan(Background()) received %v", err)
+	default:
+	}
+}
+
+func XTestActiveDeadlines(t testingT) {
+	d := time.Now().Add(veryLongDuration)
+	ctx, cancel := WithDeadline(Background(), d)
+	found := func() bool {
+		for _, ad := range ActiveDeadlines() {
+			if ad.Equal(d) {
+				return true
+			}
+		}
+		return false
+	}
+	if got := found(); got != deadlineRegistryEnabled {
+		t.Errorf("deadline of %v found in ActiveDeadlines = %v, want %v", ctx, got, deadlineRegistryEnabled)
+	}
+	cancel()
+	if found() {
+		t.Errorf("deadline of canceled %v found in ActiveDeadlines", ctx)
+	}
+}
+
+func XTestCancelSourceOf(t testingT) {
+	if got := CancelSourceOf(Background()); got != NotCanceled {
+		t.Errorf("CancelSourceOf(Background()) = %v, want %v", got, NotCanceled)
+	}
+
+	parent, cancelParent := WithCancel(Background())
+	self, cancelSelf := WithCancel(parent)
+	child, cancelChild := WithTimeout(parent, veryLongDuration)
+	defer cancelChild()
+	if got := CancelSourceOf(self); got != NotCanceled {
+		t.Errorf("CancelSourceOf(uncanceled) = %v, want %v", got, NotCanceled)
+	}
+	cancelSelf()
+	cancelParent()
+	if got := CancelSourceOf(self); got != CanceledBySelf {
+		t.Errorf("CancelSourceOf(self-canceled) = %v, want %v", got, CanceledBySelf)
+	}
+	if got := CancelSourceOf(WithValue(self, k1, "v")); got != CanceledBySelf {
+		t.Errorf("CancelSourceOf(WithValue(self-canceled)) = %v, want %v", got, CanceledBySelf)
+	}
+	if got := CancelSourceOf(parent); got != CanceledBySelf {
+		t.Errorf("CancelSourceOf(parent) = %v, want %v", got, CanceledBySelf)
+	}
+	if got := CancelSourceOf(child); got != CanceledByParent {
+		t.Errorf("CancelSourceOf(child) = %v, want %v", got, CanceledByParent)
+	}
+
+	deadline, cancel := WithTimeout(Background(), shortDuration)
+	defer cancel()
+	child, cancelChild = WithCancel(deadline)
+	defer cancelChild()
+	<-child.Done()
+	if got := CancelSourceOf(deadline); got != CanceledByDeadline {
+		t.Errorf("CancelSourceOf(expired) = %v, want %v", got, CanceledByDeadline)
+	}
+	if got := CancelSourceOf(child); got != CanceledByParent {
+		t.Errorf("CancelSourceOf(child of expired) = %v, want %v", got, CanceledByParent)
+	}
+
+	timer, cancel := WithTimeout(Background(), veryLongDuration)
+	cancel()
+	if got := CancelSourceOf(timer); got != CanceledBySelf {
+		t.Errorf("CancelSourceOf(canceled timer) = %v, want %v", got, CanceledBySelf)
+	}
+}
+
+func XTestBaggage(t testingT) {
+	if b := BaggageFrom(Background()); b.Len() != 0 {
+		t.Errorf("BaggageFrom(Background()) = %v, want empty", b)
+	}
+
+	src := map[string]string{"user": "gopher", "tenant": "a"}
+	ctx := WithBaggage(Background(), NewBaggage(src))
+	src["user"] = "modified"
+	if v, ok := BaggageFrom(ctx).Get("user"); !ok || v != "gopher" {
+		t.Errorf("Get(%q) = %q, %v, want %q, true", "user", v, ok, "gopher")
+	}
+
+	ctx = WithValue(ctx, k1, "v")
+	ctx = WithBaggage(ctx, NewBaggage(map[string]string{"tenant": "b", "span": "1"}))
+	b := BaggageFrom(ctx)
+	want := map[string]string{"user": "gopher", "tenant": "b", "span": "1"}
+	if b.Len() != len(want) {
+		t.Errorf("merged baggage %v has %d pairs, want %d", b, b.Len(), len(want))
+	}
+	b.Range(func(key, value string) bool {
+		if want[key] != value {
+			t.Errorf("merged baggage[%q] = %q, want %q", key, value, want[key])
+		}
+		return true
+	})
+	if _, ok := b.Get("missing"); ok {
+		t.Errorf("Get(%q) found a value", "missing")
+	}
+}
+
+func XTestGroup(t testingT) {
+	g, ctx := WithGroup(Background())
+	for i := 0; i < 3; i++ {
+		g.Go(func() error { return nil })
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+	if ctx.Err() != Canceled {
+		t.Errorf("ctx.Err() after Wait = %v, want %v", ctx.Err(), Canceled)
+	}
+	if err := Cause(ctx); err != Canceled {
+		t.Errorf("Cause(ctx) after Wait = %v, want %v", err, Canceled)
+	}
+
+	errFail := errors.New("fail")
+	g, ctx = WithGroup(Background())
+	sibling := make(chan error, 1)
+	g.Go(func() error {
+		<-ctx.Done()
+		sibling <- Cause(ctx)
+		return ctx.Err()
+	})
+	g.Go(func() error { return errFail })
+	if err := g.Wait(); err != errFail {
+		t.Errorf("Wait() = %v, want %v", err, errFail)
+	}
+	if err := <-sibling; err != errFail {
+		t.Errorf("Cause(ctx) seen by sibling = %v, want %v", err, errFail)
+	}
+	if ctx.Err() != Canceled {
+		t.Errorf("ctx.Err() after failure = %v, want %v", ctx.Err(), Canceled)
+	}
+}
+
+func XTestRun(t testingT) {
+	errFail := errors.New("fail")
+	if err := Run(Background(), veryLongDuration, func(Context) error { return errFail }); err != errFail {
+		t.Errorf("Run returning error = %v, want %v", err, errFail)
+	}
+
+	err := Run(Background(), veryLongDuration, func(Context) error { panic("boom") })
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Run panicking = %v, want an error mentioning the panic value", err)
+	}
+	err = Run(Background(), veryLongDuration, func(Context) error { panic(errFail) })
+	if !errors.Is(err, errFail) {
+		t.Errorf("Run panicking with %v = %v, want an error wrapping it", errFail, err)
+	}
+	type code int
+	for _, tt := range []struct {
+		v    any
+		want string
+	}{
+		{1.5, "+1.500000e+000"},
+		{complex(1, -2), "(+1.000000e+000-2.000000e+000i)"},
+		{code(7), "context.code(7)"},
+		{struct{ A int }{1}, "(struct { A int })"},
+	} {
+		err := Run(Background(), veryLongDuration, func(Context) error { panic(tt.v) })
+		if want := "context: panic in Run: " + tt.want; err == nil || err.Error() != want {
+			t.Errorf("Run panicking with %#v = %v, want %q", tt.v, err, want)
+		}
+	}
+
+	// Functions that exit without returning must not make Run wait
+	// for its timeout.
+	err = Run(Background(), veryLongDuration, func(Context) error {
+		runtime.Goexit()
+		return nil
+	})
+	if err == nil || err == DeadlineExceeded {
+		t.Errorf("Run calling runtime.Goexit = %v, want an error", err)
+	}
+	err = Run(Background(), veryLongDuration, func(Context) error { panic(nil) })
+	if err == nil || err == DeadlineExceeded {
+		t.Errorf("Run panicking with nil = %v, want an error", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	err = Run(Background(), shortDuration, func(Context) error {
+		<-release
+		return nil
+	})
+	if err != DeadlineExceeded {
+		t.Errorf("Run exceeding its timeout = %v, want %v", err, DeadlineExceeded)
+	}
+
+	parent, cancel := WithCancel(Background())
+	cancel()
+	err = Run(parent, veryLongDuration, func(Context) error {
+		<-release
+		return nil
+	})
+	if err != Canceled {
+		t.Errorf("Run with canceled parent = %v, want %v", err, Canceled)
+	}
+}
+
+func XTestInjectValues(t testingT) {
+	type injKey1 struct{}
+	type injKey2 struct{}
+	parent := WithValue(Background(), k1, "parent")
+	vals := map[any]any{injKey1{}: "a", k2: "b"}
+	c := InjectValues(parent, vals)
+	vals[injKey1{}] = "modified"
+	for _, tt := range []struct {
+		key, want any
+	}{
+		{injKey1{}, "a"},
+		{k2, "b"},
+		{k1, "parent"},
+		{injKey2{}, nil},
+		{[]int{1}, nil}, // not comparable
+	} {
+		if got := c.Value(tt.key); got != tt.want {
+			t.Errorf("c.Value(%T) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	// Injecting into an injected context merges the nodes.
+	c2 := InjectValues(c, map[any]any{injKey1{}: "c", injKey2{}: "d"})
+	m, ok := c2.(*mapValueCtx)
+	if !ok || m.Context != parent {
+		t.Fatalf("InjectValues on an injected context = %v, want a single node on top of %v", c2, parent)
+	}
+	if got := c2.Value(injKey1{}); got != "c" {
+		t.Errorf("c2.Value(injKey1) = %v, want c", got)
+	}
+	if got := c.Value(injKey1{}); got != "a" {
+		t.Errorf("after merge, c.Value(injKey1) = %v, want a", got)
+	}
+	if got := WithValue(c2, k3, "e").Value(k2); got != "b" {
+		t.Errorf("value through WithValue(c2) = %v, want b", got)
+	}
+	if got, want := c2.(stringer).String(), "context.Background.WithValue(type context.key1, val parent).InjectValues(3 values)"; got != want {
+		t.Errorf("c2.String() = %q, want %q", got, want)
+	}
+
+	if InjectValues(parent, nil) != parent {
+		t.Errorf("InjectValues with no values did not return the parent")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("InjectValues with a nil key did not panic")
+			}
+		}()
+		InjectValues(parent, map[any]any{nil: 1})
+	}()
+}
+
+func XTestWithValues(t testingT) {
+	parent := WithValue(Background(), k1, "parent")
+	c := WithValues(parent, k2, "c2", k3, "c3", k2, "c2 again")
+	for _, tt := range []struct {
+		key, want any
+	}{
+		{k1, "parent"},
+		{k2, "c2 again"}, // later pairs take precedence
+		{k3, "c3"},
+		{key2(4), nil},
+		{[]int{1}, nil}, // not comparable
+	} {
+		if got := c.Value(tt.key); got != tt.want {
+			t.Errorf("c.Value(%T(%v)) = %v, want %v", tt.key, tt.key, got, tt.want)
+		}
+	}
+	if got := WithValue(c, k1, "child").Value(k3); got != "c3" {
+		t.Errorf("value through WithValue(c) = %v, want c3", got)
+	}
+	if got, want := fmt.Sprint(c), "context.Background.WithValue(type context.key1, val parent)"+
+		".WithValues(type context.key2, val c2, type context.key2, val c3, type context.key2, val c2 again)"; got != want {
+		t.Errorf("c.String() = %q, want %q", got, want)
+	}
+
+	kvs := []any{k1, "a"}
+	c = WithValues(Background(), kvs...)
+	kvs[1] = "modified"
+	if got := c.Value(k1); got != "a" {
+		t.Errorf("after modifying kvs, c.Value(k1) = %v, want a", got)
+	}
+	if WithValues(parent) != parent {
+		t.Errorf("WithValues with no pairs did not return the parent")
+	}
+
+	for _, kvs := range [][]any{
+		{k1},
+		{k1, "a", k2},
+		{nil, "a"},
+		{k1, "a", []int{1}, "b"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("WithValues(parent, %v) did not panic", kvs)
+				}
+			}()
+			WithValues(parent, kvs...)
+		}()
+	}
+}
+
+func XTestSplitBudget(t testingT) {
+	parent, cancelParent := WithTimeout(Background(), veryLongDuration)
+	defer cancelParent()
+	deadline, _ := parent.Deadline()
+
+	ctxs, cancel := SplitBudget(parent, 4)
+	if len(ctxs) != 4 {
+		t.Fatalf("SplitBudget(parent, 4) returned %d contexts, want 4", len(ctxs))
+	}
+	var prev time.Time
+	for i, ctx := range ctxs {
+		d, ok := ctx.Deadline()
+		if !ok {
+			t.Fatalf("ctxs[%d] has no deadline", i)
+		}
+		if !d.After(prev) {
+			t.Errorf("ctxs[%d] deadline %v not after previous deadline %v", i, d, prev)
+		}
+		prev = d
+	}
+	if d, _ := ctxs[0].Deadline(); time.Until(d) > veryLongDuration/4 {
+		t.Errorf("ctxs[0] deadline %v is more than a quarter of the budget away", d)
+	}
+	if d, _ := ctxs[3].Deadline(); !d.Equal(deadline) {
+		t.Errorf("last deadline = %v, want parent's deadline %v", d, deadline)
+	}
+	cancel()
+	for i, ctx := range ctxs {
+		if err := ctx.Err(); err != Canceled {
+			t.Errorf("after cancel, ctxs[%d].Err() = %v, want %v", i, err, Canceled)
+		}
+	}
+
+	noDeadline, cancelNoDeadline := WithCancel(Background())
+	ctxs, cancel = SplitBudget(noDeadline, 2)
+	defer cancel()
+	for i, ctx := range ctxs {
+		if _, ok := ctx.Deadline(); ok {
+			t.Errorf("ctxs[%d] of a parent without deadline has a deadline", i)
+		}
+	}
+	cancelNoDeadline()
+	for i, ctx := range ctxs {
+		if err := ctx.Err(); err != Canceled {
+			t.Errorf("after canceling parent, ctxs[%d].Err() = %v, want %v", i, err, Canceled)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("SplitBudget(ctx, 0) did not panic")
+		}
+	}()
+	SplitBudget(Background(), 0)
+}
+
+func XTestMustValue(t testingT) {
+	ctx := WithValue(Background(), k1, "id-1")
+	if got := MustValue[string](ctx, k1); got != "id-1" {
+		t.Errorf("MustValue[string](ctx, k1) = %q, want %q", got, "id-1")
+	}
+	if got := MustValue[any](ctx, k1); got != "id-1" {
+		t.Errorf("MustValue[any](ctx, k1) = %v, want %q", got, "id-1")
+	}
+
+	mustPanic := func(f func(), want string) {
+		defer func() {
+			r := recover()
+			if s, _ := r.(string); !strings.Contains(s, want) {
+				t.Errorf("panic = %v, want message containing %q", r, want)
+			}
+		}()
+		f()
+	}
+	mustPanic(func() { MustValue[string](ctx, k2) }, "no value for key of type context.key2, want string")
+	mustPanic(func() { MustValue[int](ctx, k1) }, "value for key of type context.key1 has type string, want int")
+	mustPanic(func() { MustValue[fmt.Stringer](ctx, k1) }, "has type string, want fmt.Stringer")
+	mustPanic(func() { MustValue[string](ctx, nil) }, "context: MustValue with nil key")
+}
+
+func XTestState(t testingT) {
+	if st := State(Background()); st != (ContextState{}) {
+		t.Errorf("State(Background()) = %+v, want zero", st)
+	}
+
+	d := time.Now().Add(veryLongDuration)
+	ctx, cancel := WithDeadline(Background(), d)
+	st := State(ctx)
+	if !st.HasDeadline || !st.Deadline.Equal(d) || st.Done || st.Err != nil {
+		t.Errorf("State of live context = %+v, want deadline %v and not done", st, d)
+	}
+	cancel()
+	st = State(ctx)
+	if !st.HasDeadline || !st.Done || st.Err != Canceled || st.Cause != Canceled {
+		t.Errorf("State of canceled context = %+v, want done with %v", st, Canceled)
+	}
+
+	errCause := errors.New("cause")
+	cctx, ccancel := WithCancelCause(Background())
+	if st := State(cctx); st.Cause != nil {
+		t.Errorf("State of live context = %+v, want nil Cause", st)
+	}
+	ccancel(errCause)
+	if st := State(cctx); !st.Done || st.Err != Canceled || st.Cause != errCause {
+		t.Errorf("State of context canceled with cause = %+v, want done with %v and cause %v", st, Canceled, errCause)
+	}
+
+	ctx, cancel = WithTimeout(Background(), shortDuration)
+	defer cancel()
+	<-ctx.Done()
+	if st := State(ctx); !st.Done || st.Err != DeadlineExceeded || st.Cause != DeadlineExceeded {
+		t.Errorf("State of expired context = %+v, want done with %v", st, DeadlineExceeded)
+	}
+}
+
+func XTestAll(t testingT) {
+	ctx1, cancel1 := WithCancel(WithValue(Background(), "k", "v1"))
+	ctx2, cancel2 := WithTimeout(Background(), veryLongDuration)
+	defer cancel2()
+	all, cancel := All(ctx1, ctx2)
+	defer cancel()
+
+	if _, ok := all.Deadline(); ok {
+		t.Errorf("All of a context without deadline has a deadline")
+	}
+	if v := all.Value("k"); v != "v1" {
+		t.Errorf(`all.Value("k") = %v, want "v1"`, v)
+	}
+	if got, want := fmt.Sprint(all), "context.All(context.Background.WithValue(type string, val v1).WithCancel, context.Background.WithDeadline("; !strings.HasPrefix(got, want) {
+		t.Errorf("all.String() = %q, want prefix %q", got, want)
+	}
+
+	cancel1()
+	select {
+	case <-all.Done():
+		t.Fatalf("All done after only one of two contexts was canceled")
+	case <-time.After(shortDuration):
+	}
+	if err := all.Err(); err != nil {
+		t.Errorf("all.Err() = %v before all contexts are done, want nil", err)
+	}
+
+	// A child of the All context is canceled along with it.
+	child, cancelChild := WithCancel(all)
+	defer cancelChild()
+
+	ctx2.(*timerCtx).cancel(false, DeadlineExceeded, nil)
+	select {
+	case <-all.Done():
+	case <-time.After(veryLongDuration):
+		t.Fatalf("All not done after every context was canceled")
+	}
+	if err := all.Err(); err != DeadlineExceeded {
+		t.Errorf("all.Err() = %v, want %v from the last context done", err, DeadlineExceeded)
+	}
+	if src := CancelSourceOf(all); src != CanceledByParent {
+		t.Errorf("CancelSourceOf(all) = %v after all contexts are done, want %v", src, CanceledByParent)
+	}
+	<-child.Done()
+
+	ctx3, cancel3 := WithCancel(Background())
+	defer cancel3()
+	all, cancel = All(ctx3, Background())
+	cancel()
+	if err := all.Err(); err != Canceled {
+		t.Errorf("after cancel, all.Err() = %v, want %v", err, Canceled)
+	}
+	if src := CancelSourceOf(all); src != CanceledBySelf {
+		t.Errorf("after cancel, CancelSourceOf(all) = %v, want %v", src, CanceledBySelf)
+	}
+	all, cancel = All(ctx3, ctx2)
+	cancel()
+	if src := CancelSourceOf(all); src != CanceledBySelf {
+		t.Errorf("after cancel, CancelSourceOf(all) = %v, want %v", src, CanceledBySelf)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("All() did not panic")
+		}
+	}()
+	All()
+}
+
+func XTestCauseChain(t testingT) {
+	if chain := CauseChain(Background()); chain != nil {
+		t.Errorf("CauseChain(Background()) = %v, want nil", chain)
+	}
+
+	root, cancelRoot := WithCancel(Background())
+	mid, cancelMid := WithTimeout(WithValue(root, "k", "v"), veryLongDuration)
+	defer cancelMid()
+	leaf, cancelLeaf := WithCancel(mid)
+	defer cancelLeaf()
+	if chain := CauseChain(leaf); chain != nil {
+		t.Errorf("CauseChain of a live context = %v, want nil", chain)
+	}
+
+	// Only the leaf and its parent have been canceled.
+	mid.(*timerCtx).cancel(true, DeadlineExceeded, nil)
+	chain := CauseChain(leaf)
+	if len(chain) != 2 || chain[0] != DeadlineExceeded || chain[1] != DeadlineExceeded {
+		t.Errorf("CauseChain after parent expired = %v, want [%v %v]", chain, DeadlineExceeded, DeadlineExceeded)
+	}
+
+	cancelRoot()
+	chain = CauseChain(leaf)
+	if len(chain) != 3 || chain[2] != Canceled {
+		t.Errorf("CauseChain after root canceled = %v, want 3 errors ending with %v", chain, Canceled)
+	}
+
+	all, cancelAll := All(leaf)
+	defer cancelAll()
+	<-all.Done()
+	if chain := CauseChain(all); len(chain) != 1 {
+		t.Errorf("CauseChain(All(leaf)) = %v, want 1 error", chain)
+	}
+	// Causes given to a CancelCauseFunc are reported rather than
+	// Canceled, and are inherited by children canceled later.
+	errRoot := errors.New("root cause")
+	errChild := errors.New("child cause")
+	croot, cancelCroot := WithCancelCause(Background())
+	cmid, cancelCmid := WithCancelCause(croot)
+	cleaf, cancelCleaf := WithCancel(WithValue(cmid, "k", "v"))
+	defer cancelCleaf()
+	cancelCmid(errChild)
+	cancelCroot(errRoot)
+	chain = CauseChain(cleaf)
+	if len(chain) != 3 || chain[0] != errChild || chain[1] != errChild || chain[2] != errRoot {
+		t.Errorf("CauseChain with causes = %v, want [%v %v %v]", chain, errChild, errChild, errRoot)
+	}
+}
+
+func XTestWithIdleTimeout(t testingT) {
+	const idle = 100 * time.Millisecond
+	ctx, reset, cancel := WithIdleTimeout(Background(), idle)
+	defer cancel()
+	if got, want := fmt.Sprint(ctx), "context.Background.WithIdleTimeout(100ms)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// Keep the context alive for several idle periods.
+	start := time.Now()
+	for time.Since(start) < 3*idle {
+		reset()
+		d, ok := ctx.Deadline()
+		if !ok || time.Until(d) > idle {
+			t.Fatalf("Deadline() = %v, %v after reset, want within %v", d, ok, idle)
+		}
+		if err := ctx.Err(); err != nil {
+			t.Fatalf("context canceled while active: %v", err)
+		}
+		time.Sleep(idle / 10)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(veryLongDuration):
+		t.Fatalf("context not canceled after being idle")
+	}
+	if err := ctx.Err(); err != DeadlineExceeded {
+		t.Errorf("Err() = %v, want %v", err, DeadlineExceeded)
+	}
+	if src := CancelSourceOf(ctx); src != CanceledByDeadline {
+		t.Errorf("CancelSourceOf = %v, want %v", src, CanceledByDeadline)
+	}
+	reset() // no effect once done
+
+	// A parent's earlier deadline is reported, and canceling the
+	// parent cancels the context.
+	parent, cancelParent := WithTimeout(Background(), veryLongDuration)
+	ctx, _, cancel = WithIdleTimeout(parent, 2*veryLongDuration)
+	defer cancel()
+	if d, _ := ctx.Deadline(); time.Until(d) > veryLongDuration {
+		t.Errorf("Deadline() = %v, want parent's deadline", d)
+	}
+	pc, _ := parentCancelCtx(parent)
+	if len(pc.children) != 1 {
+		t.Errorf("parent has %d children, want 1", len(pc.children))
+	}
+	cancel()
+	if len(pc.children) != 0 {
+		t.Errorf("parent has %d children after cancel, want 0", len(pc.children))
+	}
+	ctx, _, cancel = WithIdleTimeout(parent, 2*veryLongDuration)
+	defer cancel()
+	cancelParent()
+	if err := ctx.Err(); err != Canceled {
+		t.Errorf("after canceling parent, Err() = %v, want %v", err, Canceled)
+	}
+
+	ctx, _, cancel = WithIdleTimeout(Background(), 0)
+	defer cancel()
+	if err := ctx.Err(); err != DeadlineExceeded {
+		t.Errorf("with zero idle timeout, Err() = %v, want %v", err, DeadlineExceeded)
+	}
+}
+
+func XTestDeadlineOwner(t testingT) {
+	if owner, ok := DeadlineOwner(Background()); ok {
+		t.Errorf("DeadlineOwner(Background()) = %v, true; want false", owner)
+	}
+
+	outer, cancelOuter := WithTimeout(Background(), veryLongDuration)
+	defer cancelOuter()
+	inner, cancelInner := WithTimeout(WithValue(outer, "k", "v"), shortDuration)
+	defer cancelInner()
+	later, cancelLater := WithTimeout(inner, veryLongDuration)
+	defer cancelLater()
+	leaf, cancelLeaf := WithCancel(WithValue(later, "k", "v"))
+	defer cancelLeaf()
+
+	for _, test := range []struct {
+		ctx, want Context
+	}{
+		{outer, outer},
+		{inner, inner},
+		{later, inner},
+		{leaf, inner},
+	} {
+		owner, ok := DeadlineOwner(test.ctx)
+		if !ok || owner != test.want {
+			t.Errorf("DeadlineOwner(%v) = %v, %v; want %v, true", test.ctx, owner, ok, test.want)
+		}
+	}
+
+	other := otherContext{leaf}
+	if owner, ok := DeadlineOwner(other); !ok || owner != other {
+		t.Errorf("DeadlineOwner(otherContext) = %v, %v; want the otherContext itself", owner, ok)
+	}
+}
+
+func XTestCloseOnCancel(t testingT) {
+	ctx, cancel := WithCancel(Background())
+	ch := make(chan struct{})
+	stop := CloseOnCancel(ctx, ch)
+	defer stop()
+	select {
+	case <-ch:
+		t.Fatalf("channel closed before cancel")
+	case <-time.After(shortDuration):
+	}
+	cancel()
+	select {
+	case <-ch:
+	case <-time.After(veryLongDuration):
+		t.Fatalf("channel not closed after cancel")
+	}
+
+	ctx, cancel = WithCancel(Background())
+	ch = make(chan struct{})
+	stop = CloseOnCancel(ctx, ch)
+	stop()
+	stop()
+	cancel()
+	select {
+	case <-ch:
+		t.Errorf("channel closed after stop")
+	case <-time.After(shortDuration):
+	}
+
+	stop = CloseOnCancel(Background(), ch)
+	stop()
+}
+
+func XTestCause(t testingT) {
+	var (
+		forever       = 1e6 * time.Second
+		parentCause   = fmt.Errorf("parentCause")
+		childCause    = fmt.Errorf("childCause")
+		tooSlow       = fmt.Errorf("tooSlow")
+		finishedEarly = fmt.Errorf("finishedEarly")
+	)
+	for _, test := range []struct {
+		name  string
+		ctx   func() Context
+		err   error
+		cause error
+	}{
+		{
+			name:  "Background",
+			ctx:   Background,
+			err:   nil,
+			cause: nil,
+		},
+		{
+			name: "WithCancel",
+			ctx: func() Context {
+				ctx, cancel := WithCancel(Background())
+				cancel()
+				return ctx
+			},
+			err:   Canceled,
+			cause: Canceled,
+		},
+		{
+			name: "WithCancelCause",
+			ctx: func() Context {
+				ctx, cancel := WithCancelCause(Background())
+				cancel(parentCause)
+				return ctx
+			},
+			err:   Canceled,
+			cause: parentCause,
+		},
+		{
+			name: "WithCancelCause nil",
+			ctx: func() Context {
+				ctx, cancel := WithCancelCause(Background())
+				cancel(nil)
+				return ctx
+			},
+			err:   Canceled,
+			cause: Canceled,
+		},
+		{
+			name: "WithCancelCause not canceled",
+			ctx: func() Context {
+				ctx, _ := WithCancelCause(Background())
+				return ctx
+			},
+			err:   nil,
+			cause: nil,
+		},
+		{
+			name: "parent canceled before child",
+			ctx: func() Context {
+				ctx, cancelParent := WithCancelCause(Background())
+				ctx, cancelChild := WithCancelCause(ctx)
+				cancelParent(parentCause)
+				cancelChild(childCause)
+				return ctx
+			},
+			err:   Canceled,
+			cause: parentCause,
+		},
+		{
+			name: "child canceled before parent",
+			ctx: func() Context {
+				ctx, cancelParent := WithCancelCause(Background())
+				ctx, cancelChild := WithCancelCause(ctx)
+				cancelChild(childCause)
+				cancelParent(parentCause)
+				return ctx
+			},
+			err:   Canceled,
+			cause: childCause,
+		},
+		{
+			name: "parent canceled through WithValue and WithTimeout",
+			ctx: func() Context {
+				ctx, cancelParent := WithCancelCause(Background())
+				ctx = WithValue(ctx, k1, "v")
+				ctx, cancelChild := WithTimeout(ctx, forever)
+				defer cancelChild()
+				cancelParent(parentCause)
+				return ctx
+			},
+			err:   Canceled,
+			cause: parentCause,
+		},
+		{
+			name: "parent of custom type",
+			ctx: func() Context {
+				ctx, cancelParent := WithCancelCause(Background())
+				ctx, cancelChild := WithCancel(otherContext{ctx})
+				defer cancelChild()
+				cancelParent(parentCause)
+				<-ctx.Done() // propagated by a goroutine
+				return ctx
+			},
+			err:   Canceled,
+			cause: parentCause,
+		},
+		{
+			name: "deadline exceeded",
+			ctx: func() Context {
+				ctx, cancel := WithTimeout(Background(), 0)
+				defer cancel()
+				return ctx
+			},
+			err:   DeadlineExceeded,
+			cause: DeadlineExceeded,
+		},
+		{
+			name: "deadline exceeded before cause",
+			ctx: func() Context {
+				ctx, cancel := WithCancelCause(Background())
+				defer cancel(finishedEarly)
+				ctx, cancelTimeout := WithTimeout(ctx, 0)
+				defer cancelTimeout()
+				cancel(tooSlow)
+				return ctx
+			},
+			err:   DeadlineExceeded,
+			cause: DeadlineExceeded,
+		},
+	} {
+		ctx := test.ctx()
+		if got, want := ctx.Err(), test.err; want != got {
+			t.Errorf("%s: ctx.Err() = %v want %v", test.name, got, want)
+		}
+		if got, want := Cause(ctx), test.cause; want != got {
+			t.Errorf("%s: Cause(ctx) = %v want %v", test.name, got, want)
+		}
+	}
+}
+
+func XTestAfterFunc(t testingT) {
+	g := atomic.LoadInt32(&goroutines)
+	ctx, cancel := WithCancel(Background())
+	donec := make(chan struct{})
+	stop := AfterFunc(ctx, func() { close(donec) })
+	if n := atomic.LoadInt32(&goroutines) - g; n != 0 {
+		t.Errorf("AfterFunc on a cancelCtx started %d goroutines, want 0", n)
+	}
+	select {
+	case <-donec:
+		t.Fatalf("AfterFunc called f before ctx was canceled")
+	case <-time.After(shortDuration):
+	}
+	cancel()
+	select {
+	case <-donec:
+	case <-time.After(veryLongDuration):
+		t.Fatalf("AfterFunc did not call f after ctx was canceled")
+	}
+	if stop() {
+		t.Errorf("stop() = true after f ran, want false")
+	}
+
+	// Stopping before the context is done keeps f from running and
+	// unregisters it from the parent.
+	ctx, cancel = WithCancel(Background())
+	stop = AfterFunc(ctx, func() { t.Errorf("stopped AfterFunc called f") })
+	if !stop() {
+		t.Errorf("first stop() = false, want true")
+	}
+	if stop() {
+		t.Errorf("second stop() = true, want false")
+	}
+	if n := len(ctx.(*cancelCtx).children); n != 0 {
+		t.Errorf("after stop, ctx has %d children, want 0", n)
+	}
+	cancel()
+
+	// f is still run for an already-canceled context.
+	ctx, cancel = WithCancel(Background())
+	cancel()
+	donec = make(chan struct{})
+	AfterFunc(ctx, func() { close(donec) })
+	select {
+	case <-donec:
+	case <-time.After(veryLongDuration):
+		t.Fatalf("AfterFunc did not call f for an already-canceled ctx")
+	}
+
+	// A context with its own Done channel needs a goroutine to wait
+	// on it.
+	g = atomic.LoadInt32(&goroutines)
+	stop = AfterFunc(&myDoneCtx{Background()}, func() { t.Errorf("AfterFunc called f for a context that is never done") })
+	if n := atomic.LoadInt32(&goroutines) - g; n != 1 {
+		t.Errorf("AfterFunc on a custom context started %d goroutines, want 1", n)
+	}
+	if !stop() {
+		t.Errorf("stop() for a custom context = false, want true")
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !contextdebug
+
+package context
+
+import "time"
+
+const deadlineRegistryEnabled = false
+
+func registerDeadline(c *timerCtx) {}
+
+func unregisterDeadline(c *timerCtx) {}
+
+func activeDeadlines() []time.Time {
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build contextdebug
+
+package context
+
+import (
+	"sync"
+	"time"
+)
+
+const deadlineRegistryEnabled = true
+
+// deadlineRegistry records the deadline of every timerCtx whose timer
+// is pending. An entry is removed when its context is canceled, which
+// at the latest happens when its timer fires. The timer already refers
+// to the context until then, so the registry does not extend the
+// lifetime of any context.
+var deadlineRegistry struct {
+	mu sync.Mutex
+	m  map[*timerCtx]time.Time
+}
+
+func registerDeadline(c *timerCtx) {
+	deadlineRegistry.mu.Lock()
+	if deadlineRegistry.m == nil {
+		deadlineRegistry.m = make(map[*timerCtx]time.Time)
+	}
+	deadlineRegistry.m[c] = c.deadline
+	deadlineRegistry.mu.Unlock()
+}
+
+func unregisterDeadline(c *timerCtx) {
+	deadlineRegistry.mu.Lock()
+	delete(deadlineRegistry.m, c)
+	deadlineRegistry.mu.Unlock()
+}
+
+func activeDeadlines() []time.Time {
+	deadlineRegistry.mu.Lock()
+	defer deadlineRegistry.mu.Unlock()
+	ds := make([]time.Time, 0, len(deadlineRegistry.m))
+	for _, d := range deadlineRegistry.m {
+		ds = append(ds, d)
+	}
+	return ds
+}
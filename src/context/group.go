@@ -0,0 +1,57 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import "sync"
+
+// A Group is a collection of goroutines working on subtasks of a common
+// task. The first subtask to fail cancels the Context returned by
+// WithGroup, so that its siblings can stop early.
+//
+// A Group must be created with WithGroup.
+type Group struct {
+	cancel CancelCauseFunc
+	wg     sync.WaitGroup
+
+	errOnce sync.Once
+	err     error
+}
+
+// WithGroup returns a new Group and an associated Context derived from
+// parent.
+//
+// The derived Context is canceled the first time a function passed to
+// Go returns a non-nil error or the first time Wait returns, whichever
+// occurs first. In the first case, Cause reports that error for the
+// derived Context.
+func WithGroup(parent Context) (*Group, Context) {
+	ctx, cancel := WithCancelCause(parent)
+	return &Group{cancel: cancel}, ctx
+}
+
+// Go calls f in a new goroutine.
+//
+// The first call to return a non-nil error cancels the group's Context;
+// its error will be returned by Wait.
+func (g *Group) Go(f func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := f(); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel(err)
+			})
+		}
+	}()
+}
+
+// Wait blocks until all function calls from the Go method have returned,
+// then returns the first non-nil error (if any) from them.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(g.err)
+	return g.err
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import "time"
+
+// WithIdleTimeout returns a copy of parent that is canceled with
+// DeadlineExceeded once idle has passed without a call to reset, for
+// example to close a streaming connection on which no data has flowed
+// for a while. Each call to reset pushes the deadline back to idle from
+// now. The returned context's Done channel is also closed when the
+// returned cancel function is called or when the parent context's Done
+// channel is closed, whichever happens first.
+//
+// The Deadline method of the returned context reports the current
+// deadline, which moves with each call to reset, or the parent's
+// deadline if that is earlier. Calling reset after the context is done
+// has no effect.
+//
+// Canceling this context releases resources associated with it, so code
+// should call cancel as soon as the operations running in this Context
+// complete.
+func WithIdleTimeout(parent Context, idle time.Duration) (ctx Context, reset func(), cancel CancelFunc) {
+	if parent == nil {
+		panic("cannot create context from nil parent")
+	}
+	c := &idleCtx{
+		timerCtx: timerCtx{
+			cancelCtx: newCancelCtx(parent),
+			deadline:  time.Now().Add(idle),
+		},
+		idle: idle,
+	}
+	propagateCancel(parent, c)
+	if idle <= 0 {
+		c.cancel(true, DeadlineExceeded, nil) // deadline has already passed
+		return c, func() {}, func() { c.cancel(false, Canceled, nil) }
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.timer = time.AfterFunc(idle, c.expire)
+		registerDeadline(&c.timerCtx)
+	}
+	return c, c.reset, func() { c.cancel(true, Canceled, nil) }
+}
+
+// An idleCtx is a timerCtx whose deadline moves on each call to reset.
+// Unlike for other timerCtxs, the deadline is guarded by cancelCtx.mu.
+//
+// reset only moves the deadline, without touching the timer, which
+// can therefore fire before the deadline; expire then restarts it for
+// the remaining time.
+type idleCtx struct {
+	timerCtx
+	idle time.Duration
+}
+
+func (c *idleCtx) Deadline() (deadline time.Time, ok bool) {
+	c.mu.Lock()
+	deadline = c.deadline
+	c.mu.Unlock()
+	if cur, ok := c.cancelCtx.Context.Deadline(); ok && cur.Before(deadline) {
+		return cur, true
+	}
+	return deadline, true
+}
+
+func (c *idleCtx) String() string {
+	return contextName(c.cancelCtx.Context) + ".WithIdleTimeout(" + c.idle.String() + ")"
+}
+
+// reset moves the deadline to c.idle from now.
+func (c *idleCtx) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.deadline = time.Now().Add(c.idle)
+	registerDeadline(&c.timerCtx)
+}
+
+// expire cancels c if its deadline has passed, and otherwise restarts
+// the timer for the time remaining.
+func (c *idleCtx) expire() {
+	c.mu.Lock()
+	if c.timer == nil {
+		c.mu.Unlock()
+		return // already canceled
+	}
+	if d := time.Until(c.deadline); d > 0 {
+		c.timer.Reset(d)
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+	c.cancel(true, DeadlineExceeded, nil)
+}
+
+func (c *idleCtx) cancel(removeFromParent bool, err, cause error) {
+	c.cancelCtx.cancelFrom(cancelSourceOf(removeFromParent, err), false, err, cause)
+	if removeFromParent {
+		// Remove this idleCtx from its parent cancelCtx's children.
+		removeChild(c.cancelCtx.Context, c)
+	}
+	c.mu.Lock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.mu.Unlock()
+	unregisterDeadline(&c.timerCtx)
+}
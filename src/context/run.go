@@ -0,0 +1,233 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"errors"
+	"std/internal/reflectlite"
+	"time"
+	"unsafe"
+)
+
+// Run calls f in a new goroutine with a Context derived from ctx that
+// is canceled after timeout, and waits for f to return or for the
+// derived Context to be done, whichever happens first.
+//
+// Run returns the error returned by f. If f panics, the panic is
+// recovered and returned as an error; if the panic value is an error,
+// the returned error wraps it. If f neither returns nor panics with a
+// non-nil value, for example because it calls runtime.Goexit, Run
+// returns an error saying so. If the derived Context is done before f
+// returns, Run returns the Context's error, DeadlineExceeded or
+// Canceled, without waiting for f, which should stop promptly once its
+// Context is done.
+func Run(ctx Context, timeout time.Duration, f func(Context) error) error {
+	ctx, cancel := WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1) // buffered so that f can finish after Run returns
+	go func() {
+		returned := false
+		defer func() {
+			if returned {
+				return
+			}
+			if v := recover(); v != nil {
+				done <- &panicError{v}
+			} else {
+				done <- errRunNotReturned
+			}
+		}()
+		err := f(ctx)
+		returned = true
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// Prefer f's result if it finished at the same time.
+		select {
+		case err := <-done:
+			return err
+		default:
+			return ctx.Err()
+		}
+	}
+}
+
+// errRunNotReturned is the error returned by Run when its function
+// exits without returning or panicking with a non-nil value.
+var errRunNotReturned = errors.New("context: function passed to Run did not return")
+
+// panicError is the error returned by Run when its function panics.
+type panicError struct {
+	value any
+}
+
+func (e *panicError) Error() string {
+	return "context: panic in Run: " + panicValueString(e.value)
+}
+
+func (e *panicError) Unwrap() error {
+	err, _ := e.value.(error)
+	return err
+}
+
+// panicValueString formats the panic value v as the runtime does when
+// it prints an unrecovered panic. Values that are neither errors,
+// Stringers nor of a basic type are reported by their type.
+func panicValueString(v any) string {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case stringer:
+		return v.String()
+	case string:
+		return v
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+		return errors.Newf("%v", v).Error()
+	case float32:
+		return formatFloat(float64(v))
+	case float64:
+		return formatFloat(v)
+	case complex64:
+		return formatComplex(complex128(v))
+	case complex128:
+		return formatComplex(v)
+	}
+	t := reflectlite.TypeOf(v).String()
+	switch u := basicValue(v).(type) {
+	case nil:
+		return "(" + t + ")"
+	case string:
+		return t + `("` + u + `")`
+	case complex64, complex128:
+		return t + panicValueString(u)
+	default:
+		return t + "(" + panicValueString(u) + ")"
+	}
+}
+
+// basicValue returns v converted to its underlying type if that is a
+// basic type, and nil otherwise.
+func basicValue(v any) any {
+	// Values of basic types are stored indirectly in interfaces.
+	p := (*[2]unsafe.Pointer)(unsafe.Pointer(&v))[1]
+	switch reflectlite.TypeOf(v).Kind() {
+	case reflectlite.Bool:
+		return *(*bool)(p)
+	case reflectlite.Int:
+		return *(*int)(p)
+	case reflectlite.Int8:
+		return *(*int8)(p)
+	case reflectlite.Int16:
+		return *(*int16)(p)
+	case reflectlite.Int32:
+		return *(*int32)(p)
+	case reflectlite.Int64:
+		return *(*int64)(p)
+	case reflectlite.Uint:
+		return *(*uint)(p)
+	case reflectlite.Uint8:
+		return *(*uint8)(p)
+	case reflectlite.Uint16:
+		return *(*uint16)(p)
+	case reflectlite.Uint32:
+		return *(*uint32)(p)
+	case reflectlite.Uint64:
+		return *(*uint64)(p)
+	case reflectlite.Uintptr:
+		return *(*uintptr)(p)
+	case reflectlite.Float32:
+		return *(*float32)(p)
+	case reflectlite.Float64:
+		return *(*float64)(p)
+	case reflectlite.Complex64:
+		return *(*complex64)(p)
+	case reflectlite.Complex128:
+		return *(*complex128)(p)
+	case reflectlite.String:
+		return *(*string)(p)
+	}
+	return nil
+}
+
+// formatFloat formats v as the runtime prints floating-point values,
+// with 7 significant digits in the form +d.dddddde+ddd.
+func formatFloat(v float64) string {
+	switch {
+	case v != v:
+		return "NaN"
+	case v+v == v && v > 0:
+		return "+Inf"
+	case v+v == v && v < 0:
+		return "-Inf"
+	}
+
+	const n = 7 // digits printed
+	var buf [n + 7]byte
+	buf[0] = '+'
+	e := 0 // exp
+	if v == 0 {
+		if 1/v < 0 {
+			buf[0] = '-'
+		}
+	} else {
+		if v < 0 {
+			v = -v
+			buf[0] = '-'
+		}
+
+		// normalize
+		for v >= 10 {
+			e++
+			v /= 10
+		}
+		for v < 1 {
+			e--
+			v *= 10
+		}
+
+		// round
+		h := 5.0
+		for i := 0; i < n; i++ {
+			h /= 10
+		}
+		v += h
+		if v >= 10 {
+			e++
+			v /= 10
+		}
+	}
+
+	// format +d.dddd+edd
+	for i := 0; i < n; i++ {
+		s := int(v)
+		buf[i+2] = byte(s + '0')
+		v -= float64(s)
+		v *= 10
+	}
+	buf[1] = buf[2]
+	buf[2] = '.'
+
+	buf[n+2] = 'e'
+	buf[n+3] = '+'
+	if e < 0 {
+		e = -e
+		buf[n+3] = '-'
+	}
+
+	buf[n+4] = byte(e/100) + '0'
+	buf[n+5] = byte(e/10)%10 + '0'
+	buf[n+6] = byte(e%10) + '0'
+	return string(buf[:])
+}
+
+// formatComplex formats c as the runtime prints complex values.
+func formatComplex(c complex128) string {
+	return "(" + formatFloat(real(c)) + formatFloat(imag(c)) + "i)"
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import "time"
+
+// A ContextState is a snapshot of the state of a Context, as returned
+// by State, suitable for logging.
+type ContextState struct {
+	Deadline    time.Time // the Context's deadline, if HasDeadline
+	HasDeadline bool      // whether the Context has a deadline
+	Done        bool      // whether the Context's Done channel is closed
+	Err         error     // the result of the Context's Err method
+	Cause       error     // the result of Cause for the Context
+}
+
+// State returns a snapshot of ctx's deadline and cancellation state.
+// The snapshot is consistent: if Err is non-nil, Done is true and Cause
+// is non-nil, and if Err is nil, so is Cause, even if ctx is canceled
+// while State runs. State does not block.
+func State(ctx Context) ContextState {
+	var st ContextState
+	st.Deadline, st.HasDeadline = ctx.Deadline()
+	select {
+	case <-ctx.Done():
+		st.Done = true
+	default:
+	}
+	// Read Err after Done: if ctx was canceled in between, Err is
+	// non-nil while Done was not yet closed, so correct Done.
+	if st.Err = ctx.Err(); st.Err != nil {
+		st.Done = true
+		// The cause is set together with Err, so once Err is
+		// non-nil, Cause is too.
+		st.Cause = Cause(ctx)
+	}
+	return st
+}
@@ -29,3 +29,21 @@ func TestWithValueChecksKey(t *testing.T)              { XTestWithValueChecksKey
 func TestInvalidDerivedFail(t *testing.T)              { XTestInvalidDerivedFail(t) }
 func TestDeadlineExceededSupportsTimeout(t *testing.T) { XTestDeadlineExceededSupportsTimeout(t) }
 func TestCustomContextGoroutines(t *testing.T)         { XTestCustomContextGoroutines(t) }
+func TestErrChan(t *testing.T)                         { XTestErrChan(t) }
+func TestActiveDeadlines(t *testing.T)                 { XTestActiveDeadlines(t) }
+func TestCancelSourceOf(t *testing.T)                  { XTestCancelSourceOf(t) }
+func TestBaggage(t *testing.T)                         { XTestBaggage(t) }
+func TestGroup(t *testing.T)                           { XTestGroup(t) }
+func TestRun(t *testing.T)                             { XTestRun(t) }
+func TestInjectValues(t *testing.T)                    { XTestInjectValues(t) }
+func TestSplitBudget(t *testing.T)                     { XTestSplitBudget(t) }
+func TestMustValue(t *testing.T)                       { XTestMustValue(t) }
+func TestState(t *testing.T)                           { XTestState(t) }
+func TestAll(t *testing.T)                             { XTestAll(t) }
+func TestCauseChain(t *testing.T)                      { XTestCauseChain(t) }
+func TestWithIdleTimeout(t *testing.T)                 { XTestWithIdleTimeout(t) }
+func TestDeadlineOwner(t *testing.T)                   { XTestDeadlineOwner(t) }
+func TestCloseOnCancel(t *testing.T)                   { XTestCloseOnCancel(t) }
+func TestWithValues(t *testing.T)                      { XTestWithValues(t) }
+func TestCause(t *testing.T)                           { XTestCause(t) }
+func TestAfterFunc(t *testing.T)                       { XTestAfterFunc(t) }
@@ -0,0 +1,164 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A DiffOp is the kind of difference described by a DiffEntry.
+type DiffOp int
+
+const (
+	DiffAdded   DiffOp = iota + 1 // the value is only in the second document
+	DiffRemoved                   // the value is only in the first document
+	DiffChanged                   // the value differs between the documents
+)
+
+func (op DiffOp) String() string {
+	switch op {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffChanged:
+		return "changed"
+	}
+	return "DiffOp(" + strconv.Itoa(int(op)) + ")"
+}
+
+// A DiffEntry describes one difference between two JSON documents.
+type DiffEntry struct {
+	Op DiffOp
+
+	// Path locates the value in the documents as a JSON Pointer
+	// (RFC 6901), such as "/servers/0/port". The empty Path refers to
+	// the whole document.
+	Path string
+
+	// Old and New hold the compact encoding of the value in the first
+	// and second document. Old is nil if Op is DiffAdded, and New is
+	// nil if Op is DiffRemoved.
+	Old, New RawMessage
+}
+
+// Diff reports the differences between the JSON documents a and b,
+// which must both be valid JSON.
+//
+// Objects are compared member by member and arrays element by element,
+// so that a member or trailing element present in only one document is
+// reported as added or removed. Values of different kinds, and unequal
+// strings, numbers, and booleans are reported as changed as a whole.
+// Numbers are compared by their text, so 1 and 1.0 differ. If an object
+// has duplicate keys, the last one is used, as with Unmarshal.
+//
+// The entries are ordered by path, with object members in sorted order
+// and array elements in index order. If the documents are equal, Diff
+// returns no entries.
+func Diff(a, b []byte) ([]DiffEntry, error) {
+	va, err := diffDecode(a)
+	if err != nil {
+		return nil, err
+	}
+	vb, err := diffDecode(b)
+	if err != nil {
+		return nil, err
+	}
+	var entries []DiffEntry
+	diffValues(&entries, "", va, vb)
+	return entries, nil
+}
+
+// diffDecode decodes data, keeping numbers as Numbers.
+func diffDecode(data []byte) (any, error) {
+	var d decodeState
+	if err := checkValid(data, &d.scan); err != nil {
+		return nil, err
+	}
+	d.init(data)
+	d.useNumber = true
+	var v any
+	if err := d.unmarshal(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// diffValues appends to *entries the differences between a and b,
+// found at path.
+func diffValues(entries *[]DiffEntry, path string, a, b any) {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			diffObjects(entries, path, a, b)
+			return
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				p := path + "/" + strconv.Itoa(i)
+				switch {
+				case i >= len(b):
+					*entries = append(*entries, DiffEntry{Op: DiffRemoved, Path: p, Old: diffEncode(a[i])})
+				case i >= len(a):
+					*entries = append(*entries, DiffEntry{Op: DiffAdded, Path: p, New: diffEncode(b[i])})
+				default:
+					diffValues(entries, p, a[i], b[i])
+				}
+			}
+			return
+		}
+	default:
+		// nil, bool, string, or Number, all comparable.
+		if a == b {
+			return
+		}
+	}
+	*entries = append(*entries, DiffEntry{Op: DiffChanged, Path: path, Old: diffEncode(a), New: diffEncode(b)})
+}
+
+// diffObjects appends to *entries the differences between the objects
+// a and b, found at path.
+func diffObjects(entries *[]DiffEntry, path string, a, b map[string]any) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := path + "/" + pointerEscaper.Replace(k)
+		va, inA := a[k]
+		vb, inB := b[k]
+		switch {
+		case !inB:
+			*entries = append(*entries, DiffEntry{Op: DiffRemoved, Path: p, Old: diffEncode(va)})
+		case !inA:
+			*entries = append(*entries, DiffEntry{Op: DiffAdded, Path: p, New: diffEncode(vb)})
+		default:
+			diffValues(entries, p, va, vb)
+		}
+	}
+}
+
+// pointerEscaper escapes a reference token of a JSON Pointer.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// diffEncode returns the compact encoding of a decoded value.
+func diffEncode(v any) RawMessage {
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+	if err := e.marshal(v, encOpts{}); err != nil {
+		// Values decoded by Unmarshal always encode.
+		panic("json: internal error: " + err.Error())
+	}
+	return append(RawMessage(nil), e.Bytes()...)
+}
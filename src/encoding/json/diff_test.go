@@ -0,0 +1,62 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []string // "op path old new"
+	}{
+		{`1`, ` 1 `, nil},
+		{`{"a": [1, {"b": null}]}`, `{"a":[1,{"b":null}]}`, nil},
+		{`1`, `1.0`, []string{"changed  1 1.0"}},
+		{`"x"`, `["x"]`, []string{`changed  "x" ["x"]`}},
+		{
+			`{"keep": true, "drop": 1, "edit": "a<b"}`,
+			`{"keep": true, "add": {"z": 1, "y": 2}, "edit": "a>b"}`,
+			[]string{
+				`added /add  {"y":2,"z":1}`,
+				`removed /drop 1 `,
+				`changed /edit "a<b" "a>b"`,
+			},
+		},
+		{
+			`{"list": [1, 2, 3]}`,
+			`{"list": [1, 5]}`,
+			[]string{"changed /list/1 2 5", "removed /list/2 3 "},
+		},
+		{`[]`, `[null]`, []string{"added /0  null"}},
+		{`{"a/b": {"c~d": 1}}`, `{"a/b": {"c~d": 2}}`, []string{"changed /a~1b/c~0d 1 2"}},
+		{`{"a": 1, "a": 2}`, `{"a": 2}`, nil},
+	}
+	for _, tt := range tests {
+		entries, err := Diff([]byte(tt.a), []byte(tt.b))
+		if err != nil {
+			t.Errorf("Diff(%#q, %#q): %v", tt.a, tt.b, err)
+			continue
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, fmt.Sprintf("%v %s %s %s", e.Op, e.Path, e.Old, e.New))
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("Diff(%#q, %#q):\nhave %q\nwant %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	for _, in := range [][2]string{{`{`, `{}`}, {`{}`, `[1,]`}} {
+		if _, err := Diff([]byte(in[0]), []byte(in[1])); err == nil {
+			t.Errorf("Diff(%#q, %#q) succeeded, want error", in[0], in[1])
+		} else if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("Diff(%#q, %#q) = %v, want *SyntaxError", in[0], in[1], err)
+		}
+	}
+}
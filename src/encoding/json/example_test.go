@@ -273,6 +273,40 @@ func ExampleIndent() {
 	// =]
 }
 
+func ExampleRenameKeys() {
+	type Road struct {
+		RoadName   string
+		RoadNumber int
+	}
+	b, err := json.Marshal([]Road{{"Diamond Fork", 29}})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// snakeCase converts a CamelCase name to snake_case.
+	snakeCase := func(key string) string {
+		var sb strings.Builder
+		for i, r := range key {
+			if 'A' <= r && r <= 'Z' {
+				if i > 0 {
+					sb.WriteByte('_')
+				}
+				r += 'a' - 'A'
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String()
+	}
+
+	var out bytes.Buffer
+	if err := json.RenameKeys(&out, b, snakeCase); err != nil {
+		log.Fatal(err)
+	}
+	out.WriteTo(os.Stdout)
+	// Output:
+	// [{"road_name":"Diamond Fork","road_number":29}]
+}
+
 func ExampleMarshalIndent() {
 	data := map[string]int{
 		"a": 1,
@@ -5,7 +5,11 @@
 package json
 
 import (
+	"io"
+	"sort"
 	"std/bytes"
+	"std/errors"
+	"unicode/utf8"
 )
 
 // Compact appends to dst the JSON-encoded src with
@@ -14,13 +18,44 @@ func Compact(dst *bytes.Buffer, src []byte) error {
 	return compact(dst, src, false)
 }
 
+// ErrCompactLimit is returned by CompactLimited when the compacted
+// output would exceed the requested limit.
+var ErrCompactLimit = errors.New("json: compacted output exceeds limit")
+
+// CompactLimited is like Compact but appends at most maxOut bytes to dst.
+// If the compacted form of src would be longer than maxOut bytes,
+// CompactLimited stops scanning, truncates dst to its original length
+// and returns ErrCompactLimit. If src is invalid JSON and the error is
+// found before the limit is reached, the syntax error is returned instead.
+func CompactLimited(dst *bytes.Buffer, src []byte, maxOut int) error {
+	if maxOut < 0 {
+		maxOut = 0
+	}
+	return compactLimit(dst, src, false, maxOut)
+}
+
 func compact(dst *bytes.Buffer, src []byte, escape bool) error {
+	return compactLimit(dst, src, escape, -1)
+}
+
+// compactLimit implements Compact and CompactLimited.
+// A negative maxOut means there is no limit on the output size.
+func compactLimit(dst *bytes.Buffer, src []byte, escape bool, maxOut int) error {
 	origLen := dst.Len()
+	// exceeds reports whether writing n more bytes to dst
+	// would exceed maxOut.
+	exceeds := func(n int) bool {
+		return maxOut >= 0 && dst.Len()-origLen+n > maxOut
+	}
 	scan := newScanner()
 	defer freeScanner(scan)
 	start := 0
 	for i, c := range src {
 		if escape && (c == '<' || c == '>' || c == '&') {
+			if exceeds(i - start + len(`\u00XX`)) {
+				dst.Truncate(origLen)
+				return ErrCompactLimit
+			}
 			if start < i {
 				dst.Write(src[start:i])
 			}
@@ -31,6 +66,10 @@ func compact(dst *bytes.Buffer, src []byte, escape bool) error {
 		}
 		// Convert U+2028 and U+2029 (E2 80 A8 and E2 80 A9).
 		if escape && c == 0xE2 && i+2 < len(src) && src[i+1] == 0x80 && src[i+2]&^1 == 0xA8 {
+			if exceeds(i - start + len(`\u202X`)) {
+				dst.Truncate(origLen)
+				return ErrCompactLimit
+			}
 			if start < i {
 				dst.Write(src[start:i])
 			}
@@ -43,6 +82,10 @@ func compact(dst *bytes.Buffer, src []byte, escape bool) error {
 			if v == scanError {
 				break
 			}
+			if exceeds(i - start) {
+				dst.Truncate(origLen)
+				return ErrCompactLimit
+			}
 			if start < i {
 				dst.Write(src[start:i])
 			}
@@ -54,11 +97,305 @@ func compact(dst *bytes.Buffer, src []byte, escape bool) error {
 		return scan.err
 	}
 	if start < len(src) {
+		if exceeds(len(src) - start) {
+			dst.Truncate(origLen)
+			return ErrCompactLimit
+		}
 		dst.Write(src[start:])
 	}
 	return nil
 }
 
+// ErrCompactBounds is returned by CompactBounded when src nests deeper
+// or has larger objects than the requested bounds allow.
+var ErrCompactBounds = errors.New("json: input exceeds nesting depth or object member limit")
+
+// CompactBounded is like Compact but rejects input that nests objects and
+// arrays more than maxDepth levels deep or that contains an object with
+// more than maxMembers members. If either bound is exceeded,
+// CompactBounded stops scanning, truncates dst to its original length and
+// returns ErrCompactBounds. A bound less than or equal to zero means no
+// limit. If src is invalid JSON and the error is found before a bound is
+// exceeded, the syntax error is returned instead.
+func CompactBounded(dst *bytes.Buffer, src []byte, maxDepth, maxMembers int) error {
+	origLen := dst.Len()
+	scan := newScanner()
+	defer freeScanner(scan)
+	// members holds the number of members seen so far in each
+	// enclosing object, innermost last.
+	var members []int
+	start := 0
+	for i, c := range src {
+		v := scan.step(scan, c)
+		switch v {
+		case scanBeginObject, scanBeginArray:
+			if maxDepth > 0 && len(scan.parseState) > maxDepth {
+				dst.Truncate(origLen)
+				return ErrCompactBounds
+			}
+			if v == scanBeginObject {
+				members = append(members, 0)
+			}
+		case scanObjectKey:
+			members[len(members)-1]++
+			if maxMembers > 0 && members[len(members)-1] > maxMembers {
+				dst.Truncate(origLen)
+				return ErrCompactBounds
+			}
+		case scanEndObject:
+			members = members[:len(members)-1]
+		}
+		if v >= scanSkipSpace {
+			if v == scanError {
+				break
+			}
+			if start < i {
+				dst.Write(src[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if scan.eof() == scanError {
+		dst.Truncate(origLen)
+		return scan.err
+	}
+	if start < len(src) {
+		dst.Write(src[start:])
+	}
+	return nil
+}
+
+// CompactStream reads JSON-encoded data from r and writes it to w with
+// insignificant space characters elided, as Compact does. It processes
+// its input incrementally, so the memory it uses does not depend on the
+// size of the input.
+//
+// Unlike Compact, CompactStream cannot take back output it has already
+// written: if the input is not valid JSON, the output up to the point
+// where the error was detected has been written to w, and CompactStream
+// returns a *SyntaxError.
+func CompactStream(w io.Writer, r io.Reader) error {
+	scan := newScanner()
+	defer freeScanner(scan)
+	buf := make([]byte, compactStreamBufSize)
+	out := make([]byte, 0, compactStreamBufSize)
+	for {
+		n, err := r.Read(buf)
+		eof := err == io.EOF
+		if err != nil && !eof {
+			return err
+		}
+		out = out[:0]
+		var serr error
+		for _, c := range buf[:n] {
+			v := scan.step(scan, c)
+			if v >= scanSkipSpace {
+				if v == scanError {
+					serr = scan.err
+					break
+				}
+				continue
+			}
+			out = append(out, c)
+		}
+		if len(out) > 0 {
+			if _, err := w.Write(out); err != nil {
+				return err
+			}
+		}
+		if serr != nil {
+			return serr
+		}
+		if eof {
+			break
+		}
+	}
+	if scan.eof() == scanError {
+		return scan.err
+	}
+	return nil
+}
+
+// compactStreamBufSize is the size of the chunks read by CompactStream.
+const compactStreamBufSize = 4 << 10
+
+// RenameKeys appends to dst the JSON-encoded src with insignificant
+// space characters elided, as Compact does, and with every object member
+// name, at any depth, replaced by the result of calling rename on it.
+// The name passed to rename is unquoted, and the result is quoted again
+// before it is written. If src is not valid JSON, RenameKeys leaves dst
+// unchanged and returns a *SyntaxError.
+func RenameKeys(dst *bytes.Buffer, src []byte, rename func(key string) string) error {
+	origLen := dst.Len()
+	scan := newScanner()
+	defer freeScanner(scan)
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+	inKey := false
+	keyStart, keyEnd := 0, 0
+	for i, c := range src {
+		scan.bytes++
+		v := scan.step(scan, c)
+		if v == scanSkipSpace || v == scanEnd {
+			continue
+		}
+		if v == scanError {
+			break
+		}
+		if v == scanBeginLiteral && len(scan.parseState) > 0 && scan.parseState[len(scan.parseState)-1] == parseObjectKey {
+			inKey = true
+			keyStart = i
+		}
+		if !inKey {
+			dst.WriteByte(c)
+			continue
+		}
+		if v != scanObjectKey {
+			keyEnd = i + 1
+			continue
+		}
+		// The key is a complete, valid string literal, so it unquotes.
+		key, _ := unquote(src[keyStart:keyEnd])
+		e.Reset()
+		e.string(rename(key), false)
+		dst.Write(e.Bytes())
+		dst.WriteByte(c)
+		inKey = false
+	}
+	if scan.eof() == scanError {
+		dst.Truncate(origLen)
+		return scan.err
+	}
+	return nil
+}
+
+// MapStrings appends to dst the JSON-encoded src with insignificant
+// space characters elided, as Compact does, and with every string value,
+// at any depth, replaced by the result of calling fn on it. Object
+// member names are not string values and are left unchanged; see
+// RenameKeys. The string passed to fn is unquoted, and the result is
+// quoted again before it is written. If src is not valid JSON,
+// MapStrings leaves dst unchanged and returns a *SyntaxError.
+func MapStrings(dst *bytes.Buffer, src []byte, fn func(s string) string) error {
+	origLen := dst.Len()
+	scan := newScanner()
+	defer freeScanner(scan)
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+	inString := false
+	strStart, strEnd := 0, 0
+	// flush writes the mapped form of the string value src[strStart:strEnd].
+	flush := func() {
+		// The value is a complete, valid string literal, so it unquotes.
+		s, _ := unquote(src[strStart:strEnd])
+		e.Reset()
+		e.string(fn(s), false)
+		dst.Write(e.Bytes())
+		inString = false
+	}
+	for i, c := range src {
+		scan.bytes++
+		v := scan.step(scan, c)
+		if inString {
+			if v == scanContinue {
+				strEnd = i + 1
+				continue
+			}
+			if v != scanError {
+				flush()
+			}
+		}
+		if v == scanSkipSpace || v == scanEnd {
+			continue
+		}
+		if v == scanError {
+			break
+		}
+		if v == scanBeginLiteral && c == '"' {
+			n := len(scan.parseState)
+			if n == 0 || scan.parseState[n-1] != parseObjectKey {
+				inString = true
+				strStart, strEnd = i, i+1
+				continue
+			}
+		}
+		dst.WriteByte(c)
+	}
+	if scan.eof() == scanError {
+		dst.Truncate(origLen)
+		return scan.err
+	}
+	if inString {
+		flush()
+	}
+	return nil
+}
+
+// Annotate appends to dst the JSON-encoded src with insignificant space
+// characters elided, as Compact does, and with every scalar value, at
+// any depth, replaced by an object giving its JSON type and the value
+// itself, such as {"$type":"number","$value":42}. The type is one of
+// "string", "number", "boolean" and "null". Object member names are not
+// values and are left unchanged. Annotate is meant for inspecting how a
+// producer typed its values, for example to tell "42" from 42. If src is
+// not valid JSON, Annotate leaves dst unchanged and returns a
+// *SyntaxError.
+func Annotate(dst *bytes.Buffer, src []byte) error {
+	origLen := dst.Len()
+	scan := newScanner()
+	defer freeScanner(scan)
+	inScalar := false
+	for _, c := range src {
+		scan.bytes++
+		v := scan.step(scan, c)
+		if inScalar {
+			if v == scanContinue {
+				dst.WriteByte(c)
+				continue
+			}
+			dst.WriteByte('}')
+			inScalar = false
+		}
+		if v == scanSkipSpace || v == scanEnd {
+			continue
+		}
+		if v == scanError {
+			break
+		}
+		if v == scanBeginLiteral {
+			n := len(scan.parseState)
+			if n == 0 || scan.parseState[n-1] != parseObjectKey {
+				dst.WriteString(`{"$type":"`)
+				dst.WriteString(literalType(c))
+				dst.WriteString(`","$value":`)
+				inScalar = true
+			}
+		}
+		dst.WriteByte(c)
+	}
+	if scan.eof() == scanError {
+		dst.Truncate(origLen)
+		return scan.err
+	}
+	if inScalar {
+		dst.WriteByte('}')
+	}
+	return nil
+}
+
+// literalType returns the JSON type of the literal starting with c.
+func literalType(c byte) string {
+	switch c {
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
 // NOTE 对于每个json子元素，需要另起一行，加上前缀和缩进。尼玛,怎么prefix是在缩进之前的...
 func newline(dst *bytes.Buffer, prefix, indent string, depth int) {
 	dst.WriteByte('\n')
@@ -149,3 +486,243 @@ func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
 	}
 	return nil
 }
+
+// IndentSize returns the number of bytes that Indent would append to dst
+// for the same arguments, without producing them. It can be used to size
+// a buffer before indenting src into it. If src is not valid JSON,
+// IndentSize returns the error that Indent would return.
+func IndentSize(src []byte, prefix, indent string) (int, error) {
+	scan := newScanner()
+	defer freeScanner(scan)
+	needIndent := false
+	depth := 0
+	n := 0
+	newline := func() { n += 1 + len(prefix) + depth*len(indent) }
+	for _, c := range src {
+		scan.bytes++
+		v := scan.step(scan, c)
+		if v == scanSkipSpace {
+			continue
+		}
+		if v == scanError {
+			break
+		}
+		if needIndent && v != scanEndObject && v != scanEndArray {
+			needIndent = false
+			depth++
+			newline()
+		}
+
+		// Bytes are counted as Indent emits them; see Indent for the
+		// layout.
+		if v == scanContinue {
+			n++
+			continue
+		}
+		switch c {
+		case '{', '[':
+			needIndent = true
+			n++
+		case ',':
+			n++
+			newline()
+		case ':':
+			n += 2
+		case '}', ']':
+			if needIndent {
+				needIndent = false
+			} else {
+				depth--
+				newline()
+			}
+			n++
+		default:
+			n++
+		}
+	}
+	if scan.eof() == scanError {
+		return 0, scan.err
+	}
+	return n, nil
+}
+
+// IndentAligned is like Indent but also aligns the members of each
+// JSON object: each key is padded with spaces to the width of the
+// longest key in the same object, so that the colons and the values
+// line up in a column. Widths are measured in runes of the key as it
+// appears in src, including its quotes.
+func IndentAligned(dst *bytes.Buffer, src []byte, prefix, indent string) error {
+	var c bytes.Buffer
+	if err := compact(&c, src, false); err != nil {
+		return err
+	}
+	indentAlignedValue(dst, c.Bytes(), prefix, indent, 0)
+	// Preserve trailing space characters as Indent does.
+	end := len(src)
+	for end > 0 && isSpace(src[end-1]) {
+		end--
+	}
+	dst.Write(src[end:])
+	return nil
+}
+
+// indentAlignedValue appends to dst the indented form of the JSON value
+// at the start of the valid, compact JSON src, nested at the given
+// depth, and returns the rest of src.
+func indentAlignedValue(dst *bytes.Buffer, src []byte, prefix, indent string, depth int) []byte {
+	switch src[0] {
+	case '{':
+		if src[1] == '}' {
+			dst.WriteString("{}")
+			return src[2:]
+		}
+		// Format the members first to find the widest key.
+		var keys, values [][]byte
+		width := 0
+		for src[0] != '}' {
+			n := stringLiteralLen(src[1:])
+			keys = append(keys, src[1:1+n])
+			if w := utf8.RuneCount(src[1 : 1+n]); w > width {
+				width = w
+			}
+			var v bytes.Buffer
+			src = indentAlignedValue(&v, src[1+n+1:], prefix, indent, depth+1)
+			values = append(values, v.Bytes())
+		}
+		dst.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				dst.WriteByte(',')
+			}
+			newline(dst, prefix, indent, depth+1)
+			dst.Write(key)
+			for n := utf8.RuneCount(key); n < width; n++ {
+				dst.WriteByte(' ')
+			}
+			dst.WriteString(": ")
+			dst.Write(values[i])
+		}
+		newline(dst, prefix, indent, depth)
+		dst.WriteByte('}')
+		return src[1:]
+	case '[':
+		if src[1] == ']' {
+			dst.WriteString("[]")
+			return src[2:]
+		}
+		dst.WriteByte('[')
+		for src[0] != ']' {
+			if src[0] == ',' {
+				dst.WriteByte(',')
+			}
+			newline(dst, prefix, indent, depth+1)
+			src = indentAlignedValue(dst, src[1:], prefix, indent, depth+1)
+		}
+		newline(dst, prefix, indent, depth)
+		dst.WriteByte(']')
+		return src[1:]
+	case '"':
+		n := stringLiteralLen(src)
+		dst.Write(src[:n])
+		return src[n:]
+	default:
+		n := 0
+		for n < len(src) && src[n] != ',' && src[n] != ']' && src[n] != '}' {
+			n++
+		}
+		dst.Write(src[:n])
+		return src[n:]
+	}
+}
+
+// IndentSortArrays is like Indent but also sorts the elements of each
+// JSON array in src, for example to produce the same output for arrays
+// whose order is insignificant. Elements are sorted with less, which
+// reports whether a must sort before b, using a stable sort. less is
+// passed the compact encoding of the elements, in which nested arrays
+// have already been sorted. If less is nil, arrays are left unsorted.
+func IndentSortArrays(dst *bytes.Buffer, src []byte, prefix, indent string, less func(a, b RawMessage) bool) error {
+	if less == nil {
+		return Indent(dst, src, prefix, indent)
+	}
+	var c bytes.Buffer
+	if err := compact(&c, src, false); err != nil {
+		return err
+	}
+	var sorted bytes.Buffer
+	sortArraysValue(&sorted, c.Bytes(), less)
+	// Preserve trailing space characters as Indent does.
+	end := len(src)
+	for end > 0 && isSpace(src[end-1]) {
+		end--
+	}
+	sorted.Write(src[end:])
+	return Indent(dst, sorted.Bytes(), prefix, indent)
+}
+
+// sortArraysValue appends to dst the JSON value at the start of the
+// valid, compact JSON src with the elements of its arrays sorted by
+// less, and returns the rest of src.
+func sortArraysValue(dst *bytes.Buffer, src []byte, less func(a, b RawMessage) bool) []byte {
+	switch src[0] {
+	case '{':
+		dst.WriteByte('{')
+		src = src[1:]
+		for src[0] != '}' {
+			if src[0] == ',' {
+				dst.WriteByte(',')
+				src = src[1:]
+			}
+			n := stringLiteralLen(src)
+			dst.Write(src[:n+1]) // the key and its colon
+			src = sortArraysValue(dst, src[n+1:], less)
+		}
+		dst.WriteByte('}')
+		return src[1:]
+	case '[':
+		var elems []RawMessage
+		src = src[1:]
+		for src[0] != ']' {
+			if src[0] == ',' {
+				src = src[1:]
+			}
+			var e bytes.Buffer
+			src = sortArraysValue(&e, src, less)
+			elems = append(elems, e.Bytes())
+		}
+		sort.SliceStable(elems, func(i, j int) bool { return less(elems[i], elems[j]) })
+		dst.WriteByte('[')
+		for i, e := range elems {
+			if i > 0 {
+				dst.WriteByte(',')
+			}
+			dst.Write(e)
+		}
+		dst.WriteByte(']')
+		return src[1:]
+	case '"':
+		n := stringLiteralLen(src)
+		dst.Write(src[:n])
+		return src[n:]
+	default:
+		n := 0
+		for n < len(src) && src[n] != ',' && src[n] != ']' && src[n] != '}' {
+			n++
+		}
+		dst.Write(src[:n])
+		return src[n:]
+	}
+}
+
+// stringLiteralLen returns the length of the valid JSON string
+// literal at the start of src, including its quotes.
+func stringLiteralLen(src []byte) int {
+	for i := 1; ; i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+}
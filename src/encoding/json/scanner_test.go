@@ -9,7 +9,9 @@ import (
 	"math"
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 var validTests = []struct {
@@ -103,6 +105,233 @@ func TestCompactSeparators(t *testing.T) {
 	}
 }
 
+func TestCompactLimited(t *testing.T) {
+	var buf bytes.Buffer
+	for _, tt := range examples {
+		n := len(tt.compact)
+		buf.Reset()
+		buf.WriteString("prefix")
+		if err := CompactLimited(&buf, []byte(tt.indent), n); err != nil {
+			t.Errorf("CompactLimited(%#q, %d): %v", tt.indent, n, err)
+		} else if s := buf.String(); s != "prefix"+tt.compact {
+			t.Errorf("CompactLimited(%#q, %d) = %#q, want %#q", tt.indent, n, s, tt.compact)
+		}
+
+		buf.Reset()
+		buf.WriteString("prefix")
+		if err := CompactLimited(&buf, []byte(tt.indent), n-1); err != ErrCompactLimit {
+			t.Errorf("CompactLimited(%#q, %d) error = %v, want %v", tt.indent, n-1, err, ErrCompactLimit)
+		} else if s := buf.String(); s != "prefix" {
+			t.Errorf("CompactLimited(%#q, %d) left %#q in dst, want %#q", tt.indent, n-1, s, "prefix")
+		}
+	}
+
+	// A syntax error before the limit is reported as such.
+	buf.Reset()
+	err := CompactLimited(&buf, []byte(`{"a" 1, "bbbbbbbbbbbbbbbbbbbbbbbb": 2}`), 10)
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("CompactLimited with early syntax error = %v, want *SyntaxError", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("CompactLimited with early syntax error left %#q in dst", buf.String())
+	}
+}
+
+func TestCompactBounded(t *testing.T) {
+	var buf bytes.Buffer
+	for _, tt := range examples {
+		buf.Reset()
+		if err := CompactBounded(&buf, []byte(tt.indent), 0, 0); err != nil {
+			t.Errorf("CompactBounded(%#q, 0, 0): %v", tt.indent, err)
+		} else if s := buf.String(); s != tt.compact {
+			t.Errorf("CompactBounded(%#q, 0, 0) = %#q, want %#q", tt.indent, s, tt.compact)
+		}
+	}
+
+	tests := []struct {
+		in                   string
+		maxDepth, maxMembers int
+		ok                   bool
+	}{
+		{`1`, 1, 1, true},
+		{`[]`, 1, 1, true},
+		{`[[]]`, 1, 0, false},
+		{`[[]]`, 2, 0, true},
+		{`{"a": [{"b": 1}]}`, 2, 0, false},
+		{`{"a": [{"b": 1}]}`, 3, 0, true},
+		{`[[], [], []]`, 2, 0, true},
+		{`{}`, 0, 1, true},
+		{`{"a": 1, "b": 2}`, 0, 1, false},
+		{`{"a": 1, "b": 2}`, 0, 2, true},
+		{`{"a": {"x": 1}, "b": {"y": 1}}`, 0, 2, true},
+		{`{"a": {"x": 1, "y": 2}}`, 0, 1, false},
+		{`[{"a": 1}, {"b": 2}, {"c": 3}]`, 0, 1, true},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		buf.WriteString("prefix")
+		err := CompactBounded(&buf, []byte(tt.in), tt.maxDepth, tt.maxMembers)
+		if tt.ok {
+			if err != nil {
+				t.Errorf("CompactBounded(%#q, %d, %d): %v", tt.in, tt.maxDepth, tt.maxMembers, err)
+			}
+			continue
+		}
+		if err != ErrCompactBounds {
+			t.Errorf("CompactBounded(%#q, %d, %d) error = %v, want %v", tt.in, tt.maxDepth, tt.maxMembers, err, ErrCompactBounds)
+		} else if s := buf.String(); s != "prefix" {
+			t.Errorf("CompactBounded(%#q, %d, %d) left %#q in dst, want %#q", tt.in, tt.maxDepth, tt.maxMembers, s, "prefix")
+		}
+	}
+
+	// A syntax error before a bound is exceeded is reported as such.
+	buf.Reset()
+	err := CompactBounded(&buf, []byte(`[1 2, [[[]]]]`), 2, 0)
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("CompactBounded with early syntax error = %v, want *SyntaxError", err)
+	}
+}
+
+func TestCompactStream(t *testing.T) {
+	var buf bytes.Buffer
+	for _, tt := range examples {
+		buf.Reset()
+		r := iotest.OneByteReader(strings.NewReader(tt.indent))
+		if err := CompactStream(&buf, r); err != nil {
+			t.Errorf("CompactStream(%#q): %v", tt.indent, err)
+		} else if s := buf.String(); s != tt.compact {
+			t.Errorf("CompactStream(%#q) = %#q, want %#q", tt.indent, s, tt.compact)
+		}
+	}
+
+	// Inputs larger than the read buffer.
+	big := "[" + strings.Repeat(`"x", `, compactStreamBufSize) + "1]"
+	want := "[" + strings.Repeat(`"x",`, compactStreamBufSize) + "1]"
+	buf.Reset()
+	if err := CompactStream(&buf, strings.NewReader(big)); err != nil || buf.String() != want {
+		t.Errorf("CompactStream(big) = %d bytes, %v, want %d bytes, nil", buf.Len(), err, len(want))
+	}
+
+	for _, in := range []string{"", "[1,", "[1] 2"} {
+		buf.Reset()
+		if err := CompactStream(&buf, strings.NewReader(in)); err == nil {
+			t.Errorf("CompactStream(%#q) succeeded, want error", in)
+		} else if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("CompactStream(%#q) error = %T, want *SyntaxError", in, err)
+		}
+	}
+}
+
+func TestRenameKeys(t *testing.T) {
+	upper := func(key string) string { return strings.ToUpper(key) }
+	tests := []struct {
+		in, out string
+	}{
+		{`1`, `1`},
+		{`{}`, `{}`},
+		{`{"a": "b"}`, `{"A":"b"}`},
+		{`{ "a" : 1, "b":[ {"c" : {"d":"e"}} ], "f" : "g h" }`, `{"A":1,"B":[{"C":{"D":"e"}}],"F":"g h"}`},
+		{`["a", {"\u0061\"b": "a"}]`, `["a",{"A\"B":"a"}]`},
+		{"{\"a\": 1}\n", `{"A":1}`},
+		{" 12 \n", `12`},
+	}
+	var buf bytes.Buffer
+	for _, tt := range tests {
+		buf.Reset()
+		if err := RenameKeys(&buf, []byte(tt.in), upper); err != nil {
+			t.Errorf("RenameKeys(%#q): %v", tt.in, err)
+		} else if s := buf.String(); s != tt.out {
+			t.Errorf("RenameKeys(%#q) = %#q, want %#q", tt.in, s, tt.out)
+		}
+	}
+
+	buf.Reset()
+	buf.WriteString("prefix")
+	err := RenameKeys(&buf, []byte(`{"a": 1, "b" 2}`), upper)
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("RenameKeys with syntax error = %v, want *SyntaxError", err)
+	}
+	if s := buf.String(); s != "prefix" {
+		t.Errorf("RenameKeys with syntax error left %#q in dst, want %#q", s, "prefix")
+	}
+}
+
+func TestMapStrings(t *testing.T) {
+	lower := func(s string) string { return strings.ToLower(s) }
+	tests := []struct {
+		in, out string
+	}{
+		{`1`, `1`},
+		{`"ABC"`, `"abc"`},
+		{` "ABC" `, `"abc"`},
+		{`{}`, `{}`},
+		{`{"A": "B"}`, `{"A":"b"}`},
+		{`{ "A" : 1, "B":[ {"C" : {"D":"E"}}, "F" ], "G" : "H I" }`, `{"A":1,"B":[{"C":{"D":"e"}},"f"],"G":"h i"}`},
+		{`["A" , true, null, "B\"C", ""]`, `["a",true,null,"b\"c",""]`},
+	}
+	var buf bytes.Buffer
+	for _, tt := range tests {
+		buf.Reset()
+		if err := MapStrings(&buf, []byte(tt.in), lower); err != nil {
+			t.Errorf("MapStrings(%#q): %v", tt.in, err)
+		} else if s := buf.String(); s != tt.out {
+			t.Errorf("MapStrings(%#q) = %#q, want %#q", tt.in, s, tt.out)
+		}
+	}
+
+	redact := func(string) string { return "<redacted>" }
+	buf.Reset()
+	if err := MapStrings(&buf, []byte(`{"pw": "x"}`), redact); err != nil {
+		t.Errorf("MapStrings with redact: %v", err)
+	} else if s, want := buf.String(), `{"pw":"<redacted>"}`; s != want {
+		t.Errorf("MapStrings with redact = %#q, want %#q", s, want)
+	}
+
+	buf.Reset()
+	buf.WriteString("prefix")
+	err := MapStrings(&buf, []byte(`{"a": "b", "c" "d"}`), lower)
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("MapStrings with syntax error = %v, want *SyntaxError", err)
+	}
+	if s := buf.String(); s != "prefix" {
+		t.Errorf("MapStrings with syntax error left %#q in dst, want %#q", s, "prefix")
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{`42`, `{"$type":"number","$value":42}`},
+		{` "42" `, `{"$type":"string","$value":"42"}`},
+		{`[]`, `[]`},
+		{`{"a": true, "b": [null, -1.5e3, "x\"y"], "c": {}}`,
+			`{"a":{"$type":"boolean","$value":true},"b":[{"$type":"null","$value":null},{"$type":"number","$value":-1.5e3},{"$type":"string","$value":"x\"y"}],"c":{}}`},
+		{`[0,false]`, `[{"$type":"number","$value":0},{"$type":"boolean","$value":false}]`},
+	}
+	var buf bytes.Buffer
+	for _, tt := range tests {
+		buf.Reset()
+		if err := Annotate(&buf, []byte(tt.in)); err != nil {
+			t.Errorf("Annotate(%#q): %v", tt.in, err)
+		} else if s := buf.String(); s != tt.out {
+			t.Errorf("Annotate(%#q) = %#q, want %#q", tt.in, s, tt.out)
+		} else if !Valid(buf.Bytes()) {
+			t.Errorf("Annotate(%#q) = %#q, which is not valid JSON", tt.in, s)
+		}
+	}
+
+	buf.Reset()
+	buf.WriteString("prefix")
+	err := Annotate(&buf, []byte(`{"a": 1,}`))
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("Annotate with syntax error = %v, want *SyntaxError", err)
+	}
+	if s := buf.String(); s != "prefix" {
+		t.Errorf("Annotate with syntax error left %#q in dst, want %#q", s, "prefix")
+	}
+}
+
 func TestIndent(t *testing.T) {
 	var buf bytes.Buffer
 	for _, tt := range examples {
@@ -123,6 +352,74 @@ func TestIndent(t *testing.T) {
 	}
 }
 
+func TestIndentSize(t *testing.T) {
+	var buf bytes.Buffer
+	for _, tt := range examples {
+		for _, in := range []string{tt.compact, tt.indent, " " + tt.compact + "\n"} {
+			buf.Reset()
+			if err := Indent(&buf, []byte(in), ">", "  "); err != nil {
+				t.Errorf("Indent(%#q): %v", in, err)
+				continue
+			}
+			if n, err := IndentSize([]byte(in), ">", "  "); n != buf.Len() || err != nil {
+				t.Errorf("IndentSize(%#q) = %d, %v, want %d, nil", in, n, err, buf.Len())
+			}
+		}
+	}
+
+	for _, tt := range indentErrorTests {
+		n, err := IndentSize([]byte(tt.in), "", "")
+		if n != 0 || !reflect.DeepEqual(err, tt.err) {
+			t.Errorf("IndentSize(%#q) = %d, %#v, want 0, %#v", tt.in, n, err, tt.err)
+		}
+	}
+}
+
+func TestIndentAligned(t *testing.T) {
+	var buf bytes.Buffer
+	for _, tt := range examples {
+		buf.Reset()
+		if err := IndentAligned(&buf, []byte(tt.compact), "", "\t"); err != nil {
+			t.Errorf("IndentAligned(%#q): %v", tt.compact, err)
+			continue
+		}
+		var c bytes.Buffer
+		if err := Compact(&c, buf.Bytes()); err != nil || c.String() != tt.compact {
+			t.Errorf("IndentAligned(%#q) = %#q, which does not compact back", tt.compact, buf.String())
+		}
+	}
+
+	in := ` {"a": 1, "long key": {"x": [true, {}], "yy": null}, "b\"": "s,}"} `
+	want := `{
+>	"a"       : 1,
+>	"long key": {
+>		"x" : [
+>			true,
+>			{}
+>		],
+>		"yy": null
+>	},
+>	"b\""     : "s,}"
+>} `
+	buf.Reset()
+	if err := IndentAligned(&buf, []byte(in), ">", "\t"); err != nil {
+		t.Fatalf("IndentAligned(%#q): %v", in, err)
+	}
+	if s := buf.String(); s != want {
+		t.Errorf("IndentAligned(%#q) =\n%s\nwant:\n%s", in, s, want)
+	}
+
+	buf.Reset()
+	buf.WriteString("prefix")
+	err := IndentAligned(&buf, []byte(`{"a": 1, "b" 2}`), "", "\t")
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("IndentAligned with syntax error = %v, want *SyntaxError", err)
+	}
+	if s := buf.String(); s != "prefix" {
+		t.Errorf("IndentAligned with syntax error left %#q in dst, want %#q", s, "prefix")
+	}
+}
+
 // Tests of a large random structure.
 
 func TestCompactBig(t *testing.T) {
@@ -139,6 +436,56 @@ func TestCompactBig(t *testing.T) {
 	}
 }
 
+func TestIndentSortArrays(t *testing.T) {
+	less := func(a, b RawMessage) bool { return string(a) < string(b) }
+	in := `{"b": [3, 1, 2], "a": [["y", "x"], ["b", "a"], []], "s": "[2,1]"}` + "\n"
+	want := `{
+>  "b": [
+>    1,
+>    2,
+>    3
+>  ],
+>  "a": [
+>    [
+>      "a",
+>      "b"
+>    ],
+>    [
+>      "x",
+>      "y"
+>    ],
+>    []
+>  ],
+>  "s": "[2,1]"
+>}
+`
+	var buf bytes.Buffer
+	if err := IndentSortArrays(&buf, []byte(in), ">", "  ", less); err != nil {
+		t.Fatalf("IndentSortArrays(%#q): %v", in, err)
+	}
+	if s := buf.String(); s != want {
+		t.Errorf("IndentSortArrays(%#q) =\n%s\nwant:\n%s", in, s, want)
+	}
+
+	// Without less, IndentSortArrays is Indent.
+	for _, tt := range examples {
+		buf.Reset()
+		if err := IndentSortArrays(&buf, []byte(tt.compact), "", "\t", nil); err != nil || buf.String() != tt.indent {
+			t.Errorf("IndentSortArrays(%#q, nil) = %#q, %v, want %#q", tt.compact, buf.String(), err, tt.indent)
+		}
+	}
+
+	buf.Reset()
+	buf.WriteString("prefix")
+	err := IndentSortArrays(&buf, []byte(`[2, 1,]`), "", "\t", less)
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("IndentSortArrays with syntax error = %v, want *SyntaxError", err)
+	}
+	if s := buf.String(); s != "prefix" {
+		t.Errorf("IndentSortArrays with syntax error left %#q in dst, want %#q", s, "prefix")
+	}
+}
+
 func TestIndentBig(t *testing.T) {
 	t.Parallel()
 	initBig()
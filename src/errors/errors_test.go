@@ -51,3 +51,82 @@ func ExampleNew_errorf() {
 	}
 	// Output: user "bimmler" (id 17) not found
 }
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+func TestNewf(t *testing.T) {
+	for _, test := range []struct {
+		format string
+		args   []any
+	}{
+		{"plain", nil},
+		{"100%%", nil},
+		{"%s: %v", []any{"open", errors.New("denied")}},
+		{"%s/%s", []any{stringer("a"), []byte("b")}},
+		{"%d %d %d", []any{0, -42, int64(-1 << 63)}},
+		{"%v %v %v", []any{uint8(255), uint64(1<<64 - 1), true}},
+		{"%v", []any{nil}},
+		{"%d", []any{"hi"}},
+		{"%s", []any{3}},
+		{"%t", []any{3}},
+		{"%w", []any{errors.New("e")}},
+		{"%s %s", []any{"a"}},
+		{"%s", []any{"a", 1, "b"}},
+		{"trailing %", nil},
+	} {
+		got := errors.Newf(test.format, test.args...).Error()
+		want := fmt.Sprintf(test.format, test.args...)
+		if test.format == "%w" {
+			want = "%!w(*errors.errorString=e)"
+		}
+		if got != want {
+			t.Errorf("Newf(%q, %v) = %q, want %q", test.format, test.args, got, want)
+		}
+	}
+}
+
+func ExampleNewf() {
+	const name, id = "bueller", 17
+	err := errors.Newf("user %s (id %d) not found", name, id)
+	fmt.Println(err)
+	// Output: user bueller (id 17) not found
+}
+
+func TestFormat(t *testing.T) {
+	eof := errors.New("unexpected EOF")
+	perm := errors.New("permission denied")
+	for _, test := range []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{eof, "unexpected EOF"},
+		{fmt.Errorf("read: %w", eof), "read\n    unexpected EOF"},
+		{fmt.Errorf("retry failed (%w)", eof), "retry failed (unexpected EOF)\n    unexpected EOF"},
+		{
+			fmt.Errorf("load config: %w", errors.JoinUnique(
+				fmt.Errorf("open a.json: %w", perm),
+				fmt.Errorf("parse b.json: %w", eof),
+			)),
+			"load config\n" +
+				"    - open a.json\n" +
+				"        permission denied\n" +
+				"    - parse b.json\n" +
+				"        unexpected EOF",
+		},
+		{errors.JoinUnique(eof, errors.New("two\nlines")), "- unexpected EOF\n- two\n  lines"},
+	} {
+		if got := errors.Format(test.err); got != test.want {
+			t.Errorf("Format(%q):\ngot:\n%s\nwant:\n%s", test.err, got, test.want)
+		}
+	}
+
+	// A cycle terminates.
+	c := &cyclicErr{}
+	c.next = c
+	if got := errors.Format(c); got == "" {
+		t.Errorf("Format on cyclic error returned nothing")
+	}
+}
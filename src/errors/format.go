@@ -0,0 +1,318 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package errors
+
+import "std/internal/reflectlite"
+
+// Newf returns an error that formats as the given format specifier
+// applied to args.
+//
+// Newf implements only a small subset of the verbs understood by
+// fmt.Errorf, so that packages that cannot or do not want to depend on
+// fmt may still create formatted errors:
+//
+//	%v	the value in a default format: the result of its Error or String
+//		method if it has one, otherwise strings, byte slices, integers
+//		and booleans
+//	%s	a string, byte slice, error or fmt.Stringer
+//	%d	an integer in base 10
+//	%%	a literal percent sign
+//
+// Flags, widths and precisions are not supported. Unlike fmt.Errorf,
+// Newf does not treat %w specially, and the returned error does not
+// wrap any of its arguments. Errors in the format are reported inline
+// in the same style as fmt, for example "%!d(string=hi)".
+func Newf(format string, args ...any) error {
+	return &errorString{sprintf(format, args)}
+}
+
+// sprintf formats args according to format. See Newf for the
+// supported verbs.
+func sprintf(format string, args []any) string {
+	buf := make([]byte, 0, len(format)+16)
+	argNum := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			buf = append(buf, c)
+			continue
+		}
+		i++
+		if i >= len(format) {
+			buf = append(buf, "%!(NOVERB)"...)
+			break
+		}
+		verb := format[i]
+		if verb == '%' {
+			buf = append(buf, '%')
+			continue
+		}
+		if argNum >= len(args) {
+			buf = append(buf, "%!"...)
+			buf = append(buf, verb)
+			buf = append(buf, "(MISSING)"...)
+			continue
+		}
+		buf = appendArg(buf, verb, args[argNum])
+		argNum++
+	}
+	if argNum < len(args) {
+		buf = append(buf, "%!(EXTRA "...)
+		for i, arg := range args[argNum:] {
+			if i > 0 {
+				buf = append(buf, ", "...)
+			}
+			buf = appendTyped(buf, arg)
+		}
+		buf = append(buf, ')')
+	}
+	return string(buf)
+}
+
+// appendArg appends arg formatted according to verb to buf.
+func appendArg(buf []byte, verb byte, arg any) []byte {
+	var (
+		b  []byte
+		ok bool
+	)
+	switch verb {
+	case 'v':
+		if arg == nil {
+			return append(buf, "<nil>"...)
+		}
+		b, ok = appendValue(buf, arg)
+	case 's':
+		b, ok = appendString(buf, arg)
+	case 'd':
+		b, ok = appendInt(buf, arg)
+	}
+	if ok {
+		return b
+	}
+	buf = append(buf, "%!"...)
+	buf = append(buf, verb)
+	buf = append(buf, '(')
+	buf = appendTyped(buf, arg)
+	return append(buf, ')')
+}
+
+// appendTyped appends arg to buf as "type=value", or just the type if
+// the value cannot be formatted.
+func appendTyped(buf []byte, arg any) []byte {
+	if arg == nil {
+		return append(buf, "<nil>"...)
+	}
+	buf = append(buf, reflectlite.TypeOf(arg).String()...)
+	if b, ok := appendValue(append(buf, '='), arg); ok {
+		return b
+	}
+	return buf
+}
+
+// appendValue appends the default format of arg to buf and reports
+// whether arg has a type Newf knows how to format.
+func appendValue(buf []byte, arg any) ([]byte, bool) {
+	if b, ok := appendString(buf, arg); ok {
+		return b, true
+	}
+	if b, ok := appendInt(buf, arg); ok {
+		return b, true
+	}
+	if v, ok := arg.(bool); ok {
+		if v {
+			return append(buf, "true"...), true
+		}
+		return append(buf, "false"...), true
+	}
+	return buf, false
+}
+
+// appendString appends arg to buf if it is a string, byte slice, error
+// or has a String method, and reports whether it did so.
+func appendString(buf []byte, arg any) ([]byte, bool) {
+	switch v := arg.(type) {
+	case error:
+		return append(buf, v.Error()...), true
+	case interface{ String() string }:
+		return append(buf, v.String()...), true
+	case string:
+		return append(buf, v...), true
+	case []byte:
+		return append(buf, v...), true
+	}
+	return buf, false
+}
+
+// appendInt appends arg to buf in base 10 if it is an integer, and
+// reports whether it did so.
+func appendInt(buf []byte, arg any) ([]byte, bool) {
+	var (
+		u   uint64
+		neg bool
+	)
+	switch v := arg.(type) {
+	case int:
+		u, neg = abs(int64(v))
+	case int8:
+		u, neg = abs(int64(v))
+	case int16:
+		u, neg = abs(int64(v))
+	case int32:
+		u, neg = abs(int64(v))
+	case int64:
+		u, neg = abs(v)
+	case uint:
+		u = uint64(v)
+	case uint8:
+		u = uint64(v)
+	case uint16:
+		u = uint64(v)
+	case uint32:
+		u = uint64(v)
+	case uint64:
+		u = v
+	case uintptr:
+		u = uint64(v)
+	default:
+		return buf, false
+	}
+	var a [20]byte
+	i := len(a)
+	for u >= 10 {
+		i--
+		a[i] = byte('0' + u%10)
+		u /= 10
+	}
+	i--
+	a[i] = byte('0' + u)
+	if neg {
+		buf = append(buf, '-')
+	}
+	return append(buf, a[i:]...), true
+}
+
+// abs returns the magnitude of v and whether v is negative.
+func abs(v int64) (uint64, bool) {
+	if v < 0 {
+		return uint64(-v), true
+	}
+	return uint64(v), false
+}
+
+// Format returns a multi-line rendering of err and the errors it wraps,
+// for logging and debugging where the single-line message returned by
+// Error hides how the errors are nested.
+//
+// Each wrapped error is shown on its own line, indented one level more
+// than the error wrapping it. A wrapping error whose message ends with
+// the message of the error it wraps, as fmt.Errorf's %w produces, is
+// shown with just the text it adds. The errors returned by an
+// Unwrap() []error method are shown as a bulleted list, directly under
+// the wrapping error's own text if it adds any:
+//
+//	load config
+//	    - open a.json: permission denied
+//	    - parse b.json
+//	        unexpected EOF
+//
+// Like Is and As, Format follows at most 100 errors along
+// each path, so an Unwrap method returning a cycle cannot make it loop
+// forever. Format returns the empty string if err is nil.
+func Format(err error) string {
+	if err == nil {
+		return ""
+	}
+	return string(appendTree(nil, err, 0, 0, false))
+}
+
+// appendTree appends to buf the lines for err, found at the given depth
+// of the tree, indented by indent levels and marked as a list item if
+// bullet is set.
+func appendTree(buf []byte, err error, depth, indent int, bullet bool) []byte {
+	if depth >= maxUnwrapDepth {
+		return buf
+	}
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		inner := x.Unwrap()
+		if inner == nil {
+			return appendLines(buf, err.Error(), indent, bullet)
+		}
+		buf = appendLines(buf, trimWrapped(err.Error(), inner.Error()), indent, bullet)
+		return appendTree(buf, inner, depth+1, indent+1, false)
+	case interface{ Unwrap() []error }:
+		errs := x.Unwrap()
+		var joined []byte
+		n := 0
+		for _, e := range errs {
+			if e == nil {
+				continue
+			}
+			if n > 0 {
+				joined = append(joined, '\n')
+			}
+			joined = append(joined, e.Error()...)
+			n++
+		}
+		if text := trimWrapped(err.Error(), string(joined)); text != "" || bullet {
+			buf = appendLines(buf, text, indent, bullet)
+			indent++
+		}
+		for _, e := range errs {
+			if e != nil {
+				buf = appendTree(buf, e, depth+1, indent, true)
+			}
+		}
+		return buf
+	}
+	return appendLines(buf, err.Error(), indent, bullet)
+}
+
+// trimWrapped returns msg without the suffix wrapped and any ": " or
+// newline before it, or msg unchanged if it does not end with wrapped.
+func trimWrapped(msg, wrapped string) string {
+	if len(wrapped) > len(msg) || msg[len(msg)-len(wrapped):] != wrapped {
+		return msg
+	}
+	msg = msg[:len(msg)-len(wrapped)]
+	for len(msg) > 0 {
+		switch msg[len(msg)-1] {
+		case ':', ' ', '\n':
+			msg = msg[:len(msg)-1]
+			continue
+		}
+		break
+	}
+	return msg
+}
+
+// appendLines appends text to buf on a new line, indented by indent
+// levels of four spaces and preceded by "- " if bullet is set. Further
+// lines of text are indented to line up with the first.
+func appendLines(buf []byte, text string, indent int, bullet bool) []byte {
+	for first := true; ; first = false {
+		if len(buf) > 0 {
+			buf = append(buf, '\n')
+		}
+		for i := 0; i < indent; i++ {
+			buf = append(buf, "    "...)
+		}
+		switch {
+		case bullet && first:
+			buf = append(buf, "- "...)
+		case bullet:
+			buf = append(buf, "  "...)
+		}
+		i := 0
+		for i < len(text) && text[i] != '\n' {
+			i++
+		}
+		buf = append(buf, text[:i]...)
+		if i == len(text) {
+			return buf
+		}
+		text = text[i+1:]
+	}
+}
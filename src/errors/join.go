@@ -0,0 +1,73 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package errors
+
+// JoinUnique returns an error that wraps the given errors, dropping nil
+// errors and any error that is equivalent to one before it. Two errors
+// are equivalent if each matches the other according to Is, as is the
+// case for equal comparable errors. JoinUnique returns nil if every
+// value in errs is nil.
+//
+// The error formats as the concatenation of the strings obtained by
+// calling the Error method of each remaining error, with a newline
+// between each string. It has a method Unwrap() []error returning the
+// remaining errors, and Is and As methods that report whether any of
+// them matches the target.
+func JoinUnique(errs ...error) error {
+	var unique []error
+outer:
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		for _, u := range unique {
+			if Is(err, u) && Is(u, err) {
+				continue outer
+			}
+		}
+		unique = append(unique, err)
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+	return &joinError{errs: unique}
+}
+
+type joinError struct {
+	errs []error
+}
+
+func (e *joinError) Error() string {
+	var b []byte
+	for i, err := range e.errs {
+		if i > 0 {
+			b = append(b, '\n')
+		}
+		b = append(b, err.Error()...)
+	}
+	return string(b)
+}
+
+func (e *joinError) Unwrap() []error {
+	return e.errs
+}
+
+func (e *joinError) Is(target error) bool {
+	for _, err := range e.errs {
+		if Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *joinError) As(target any) bool {
+	for _, err := range e.errs {
+		if As(err, target) {
+			return true
+		}
+	}
+	return false
+}
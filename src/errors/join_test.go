@@ -0,0 +1,57 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package errors_test
+
+import (
+	"io/fs"
+	"reflect"
+	"std/errors"
+	"testing"
+)
+
+func TestJoinUnique(t *testing.T) {
+	err1 := errors.New("err1")
+	err2 := errors.New("err2")
+	wrapped1 := wrapped{"wrap 1", err1}
+	for _, test := range []struct {
+		errs []error
+		want []error
+	}{
+		{[]error{err1}, []error{err1}},
+		{[]error{err1, err1, err1}, []error{err1}},
+		{[]error{err1, nil, err2, err1, err2}, []error{err1, err2}},
+		{[]error{errorT{"a"}, errorT{"a"}, errorT{"b"}}, []error{errorT{"a"}, errorT{"b"}}},
+		{[]error{errorUncomparable{}, errorUncomparable{}}, []error{errorUncomparable{}}},
+		// An error wrapping another is not equivalent to it.
+		{[]error{wrapped1, err1, wrapped1}, []error{wrapped1, err1}},
+	} {
+		got := errors.JoinUnique(test.errs...).(interface{ Unwrap() []error }).Unwrap()
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("JoinUnique(%v) = %v, want %v", test.errs, got, test.want)
+		}
+	}
+
+	if err := errors.JoinUnique(); err != nil {
+		t.Errorf("JoinUnique() = %v, want nil", err)
+	}
+	if err := errors.JoinUnique(nil, nil); err != nil {
+		t.Errorf("JoinUnique(nil, nil) = %v, want nil", err)
+	}
+
+	err := errors.JoinUnique(err1, wrapped{"missing", fs.ErrNotExist}, err1)
+	if got, want := err.Error(), "err1\nmissing"; got != want {
+		t.Errorf("JoinUnique(...).Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Is(JoinUnique(...), fs.ErrNotExist) = false, want true")
+	}
+	if errors.Is(err, err2) {
+		t.Errorf("Is(JoinUnique(...), err2) = true, want false")
+	}
+	var w wrapped
+	if !errors.As(err, &w) || w.msg != "missing" {
+		t.Errorf("As(JoinUnique(...), &wrapped) = false or wrong value %v", w)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package errors
+
+// WithRetryable returns an error that wraps err and records whether the
+// operation that failed with err may be retried. The returned error
+// formats as err does, and IsRetryable reports retryable for it and for
+// any error that wraps it without a closer annotation. WithRetryable
+// returns nil if err is nil.
+//
+// The returned error has a method Retryable() bool reporting retryable.
+// Other error types may implement the same method to classify
+// themselves without being annotated.
+func WithRetryable(err error, retryable bool) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err, retryable}
+}
+
+// IsRetryable reports whether err was classified as retryable by the
+// nearest error in its tree that has a method Retryable() bool, such as
+// one returned by WithRetryable. known reports whether there is such an
+// error; if not, retryable is false.
+//
+// The tree is searched in depth-first order, so an annotation on an
+// error takes precedence over any on the errors it wraps. As for Is and
+// As, IsRetryable examines each path through the tree for at most 100
+// errors.
+func IsRetryable(err error) (retryable bool, known bool) {
+	return isRetryable(err, 0)
+}
+
+func isRetryable(err error, depth int) (retryable bool, known bool) {
+	for ; err != nil && depth < maxUnwrapDepth; depth++ {
+		if r, ok := err.(interface{ Retryable() bool }); ok {
+			return r.Retryable(), true
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range x.Unwrap() {
+				if retryable, known := isRetryable(e, depth+1); known {
+					return retryable, true
+				}
+			}
+			return false, false
+		default:
+			return false, false
+		}
+	}
+	return false, false
+}
+
+type retryableError struct {
+	err       error
+	retryable bool
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+func (e *retryableError) Retryable() bool {
+	return e.retryable
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package errors_test
+
+import (
+	"std/errors"
+	"testing"
+)
+
+type temporaryErr struct{}
+
+func (temporaryErr) Error() string   { return "temporary" }
+func (temporaryErr) Retryable() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	base := errors.New("base")
+	retry := errors.WithRetryable(base, true)
+	noRetry := errors.WithRetryable(base, false)
+	for _, test := range []struct {
+		err              error
+		retryable, known bool
+	}{
+		{nil, false, false},
+		{base, false, false},
+		{retry, true, true},
+		{noRetry, false, true},
+		{wrapped{"wrap", retry}, true, true},
+		{wrapped{"wrap", wrapped{"wrap", noRetry}}, false, true},
+		// The nearest annotation wins.
+		{errors.WithRetryable(retry, false), false, true},
+		{errors.WithRetryable(wrapped{"wrap", noRetry}, true), true, true},
+		{multiErr{base, noRetry, retry}, false, true},
+		{multiErr{base, wrapped{"wrap", base}}, false, false},
+		{temporaryErr{}, true, true},
+		{wrapped{"wrap", temporaryErr{}}, true, true},
+	} {
+		retryable, known := errors.IsRetryable(test.err)
+		if retryable != test.retryable || known != test.known {
+			t.Errorf("IsRetryable(%v) = %v, %v, want %v, %v", test.err, retryable, known, test.retryable, test.known)
+		}
+	}
+
+	if got := retry.Error(); got != "base" {
+		t.Errorf("WithRetryable(base, true).Error() = %q, want %q", got, "base")
+	}
+	if !errors.Is(retry, base) {
+		t.Errorf("WithRetryable(base, true) does not match base")
+	}
+	if err := errors.WithRetryable(nil, true); err != nil {
+		t.Errorf("WithRetryable(nil, true) = %v, want nil", err)
+	}
+
+	c := &cyclicErr{}
+	c.next = c
+	if _, known := errors.IsRetryable(c); known {
+		t.Errorf("IsRetryable on cyclic error found an annotation")
+	}
+}
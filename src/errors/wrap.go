@@ -21,6 +21,88 @@ func Unwrap(err error) error {
 	return u.Unwrap()
 }
 
+// Flatten returns the leaf errors of err's tree in depth-first order.
+//
+// The tree consists of err itself, followed by the errors obtained by
+// repeatedly calling Unwrap. An error type may also wrap multiple errors
+// by providing a method Unwrap() []error, in which case each of the
+// returned errors is visited in turn. A leaf is an error that does not
+// wrap any further non-nil error.
+//
+// Flatten returns nil if err is nil, and a one-element slice containing
+// err if err wraps no other errors.
+//
+// Like Is and As, Flatten follows each path through the tree for at
+// most 100 errors, so that an Unwrap method returning a cycle cannot
+// make it recurse forever; the 100th error on a path is treated as a
+// leaf.
+func Flatten(err error) []error {
+	if err == nil {
+		return nil
+	}
+	return flatten(nil, err, 0)
+}
+
+// flatten appends the leaves of err's tree to leaves, where depth is
+// the number of errors above err on its path.
+func flatten(leaves []error, err error, depth int) []error {
+	if depth+1 < maxUnwrapDepth {
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			if e := x.Unwrap(); e != nil {
+				return flatten(leaves, e, depth+1)
+			}
+		case interface{ Unwrap() []error }:
+			n := len(leaves)
+			for _, e := range x.Unwrap() {
+				if e != nil {
+					leaves = flatten(leaves, e, depth+1)
+				}
+			}
+			if len(leaves) > n {
+				return leaves
+			}
+		}
+	}
+	return append(leaves, err)
+}
+
+// AsAll returns every error in err's tree that has type T, or, if T is
+// an interface type, that implements T, in depth-first order. It
+// returns nil if there are none.
+//
+// The tree consists of err itself, followed by the errors obtained by
+// repeatedly calling Unwrap, where an error may wrap several errors by
+// providing a method Unwrap() []error, as for Flatten. Unlike As,
+// AsAll does not call As methods. Like Is and As, AsAll follows each
+// path through the tree for at most 100 errors.
+func AsAll[T error](err error) []T {
+	var all []T
+	walk(err, 0, func(err error) {
+		if t, ok := err.(T); ok {
+			all = append(all, t)
+		}
+	})
+	return all
+}
+
+// walk calls f for err and every error in its tree, in depth-first
+// order, where depth is the number of errors above err on its path.
+func walk(err error, depth int, f func(error)) {
+	if err == nil || depth >= maxUnwrapDepth {
+		return
+	}
+	f(err)
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		walk(x.Unwrap(), depth+1, f)
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			walk(e, depth+1, f)
+		}
+	}
+}
+
 // Is reports whether any error in err's chain matches target.
 //
 // The chain consists of err itself followed by the sequence of errors obtained by
@@ -57,13 +139,17 @@ func Unwrap(err error) error {
 // note 用来比较两个err是否是同种类型的；如果err实现了Is接口，则直接调用Is来判相等，否则如果实现了Unwrap接口，则调用Unwrap来解包装
 // note Unwrap主要用在包装err从而添加err信息属性的场景，比如struct{err,msg}，这时候需要通过Unwrap来解包装得到真正的err
 // 通过for来不断解包装，直到拿到真正的err
+//
+// Is examines at most 100 errors in the chain, so that an Unwrap method
+// returning a cycle cannot make it loop forever. If no match is found
+// within that depth, Is returns false.
 func Is(err, target error) bool {
 	if target == nil {
 		return err == target
 	}
 
 	isComparable := reflectlite.TypeOf(target).Comparable()
-	for {
+	for depth := 0; depth < maxUnwrapDepth; depth++ {
 		if isComparable && err == target {
 			return true
 		}
@@ -77,6 +163,7 @@ func Is(err, target error) bool {
 			return false
 		}
 	}
+	return false
 }
 
 // As finds the first error in err's chain that matches target, and if one is found, sets
@@ -97,6 +184,8 @@ func Is(err, target error) bool {
 // error, or to any interface type.
 
 // 不断将err解包，直到其类型满足要求后，将其值赋给target，或者是其实现了As接口，则直接调用As方法，然后返回
+//
+// Like Is, As examines at most 100 errors in the chain.
 func As(err error, target any) bool {
 	if target == nil {
 		panic("errors: target cannot be nil")
@@ -111,7 +200,7 @@ func As(err error, target any) bool {
 	if targetType.Kind() != reflectlite.Interface && !targetType.Implements(errorType) {
 		panic("errors: *target must be interface or implement error")
 	}
-	for err != nil {
+	for depth := 0; err != nil && depth < maxUnwrapDepth; depth++ {
 		if reflectlite.TypeOf(err).AssignableTo(targetType) { // 能赋值则将err赋值给targetType
 			val.Elem().Set(reflectlite.ValueOf(err))
 			return true
@@ -125,3 +214,9 @@ func As(err error, target any) bool {
 }
 
 var errorType = reflectlite.TypeOf((*error)(nil)).Elem()
+
+// maxUnwrapDepth is the maximum number of errors in a chain examined by
+// Is and As. Real chains are far shorter; the limit only guards against
+// Unwrap methods that return cycles. The docs of the exported functions
+// state the limit in words, and must be updated if it changes.
+const maxUnwrapDepth = 100
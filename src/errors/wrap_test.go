@@ -192,6 +192,23 @@ func TestAsValidation(t *testing.T) {
 	}
 }
 
+func TestIsAsCycle(t *testing.T) {
+	err := &cyclicErr{}
+	err.next = err
+	target := errors.New("target")
+	if errors.Is(err, target) {
+		t.Errorf("Is(cyclic, target) = true, want false")
+	}
+	var et errorT
+	if errors.As(err, &et) {
+		t.Errorf("As(cyclic, %T) = true, want false", &et)
+	}
+	var ce *cyclicErr
+	if !errors.As(err, &ce) || ce != err {
+		t.Errorf("As(cyclic, %T) did not find the cyclic error", &ce)
+	}
+}
+
 func TestUnwrap(t *testing.T) {
 	err1 := errors.New("1")
 	erra := wrapped{"wrap 2", err1}
@@ -213,6 +230,80 @@ func TestUnwrap(t *testing.T) {
 	}
 }
 
+func TestFlatten(t *testing.T) {
+	err1 := errors.New("1")
+	err2 := errors.New("2")
+	err3 := errors.New("3")
+	erra := wrapped{"wrap 2", err1}
+	errb := wrapped{"wrap 3", nil}
+
+	testCases := []struct {
+		err  error
+		want []error
+	}{
+		{nil, nil},
+		{err1, []error{err1}},
+		{erra, []error{err1}},
+		{errb, []error{errb}},
+		{multiErr{err1, err2}, []error{err1, err2}},
+		{multiErr{multiErr{erra, nil}, err3}, []error{err1, err3}},
+		{wrapped{"wrap multi", multiErr{err2, multiErr{err3}}}, []error{err2, err3}},
+		{multiErr{}, []error{multiErr{}}},
+	}
+	for _, tc := range testCases {
+		if got := errors.Flatten(tc.err); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Flatten(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+
+	c := &cyclicErr{}
+	c.next = c
+	if got := errors.Flatten(c); len(got) != 1 || got[0] != c {
+		t.Errorf("Flatten on cyclic error = %v, want [%v]", got, c)
+	}
+	m := multiErr{nil}
+	m[0] = wrapped{"wrap", &m}
+	if got := errors.Flatten(m); len(got) == 0 {
+		t.Errorf("Flatten on cyclic multi-error found nothing")
+	}
+}
+
+func TestAsAll(t *testing.T) {
+	err1 := errors.New("1")
+	ta, tb, tc := errorT{"a"}, errorT{"b"}, errorT{"c"}
+	err := multiErr{
+		wrapped{"wrap a", ta},
+		err1,
+		multiErr{tb, wrapped{"wrap multi", multiErr{tc, nil}}},
+	}
+	if got, want := errors.AsAll[errorT](err), []errorT{ta, tb, tc}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AsAll[errorT] = %v, want %v", got, want)
+	}
+	if got := errors.AsAll[wrapped](err); len(got) != 2 || got[0].msg != "wrap a" || got[1].msg != "wrap multi" {
+		t.Errorf("AsAll[wrapped] = %v, want the two wrapped errors", got)
+	}
+	type multiError interface {
+		error
+		Unwrap() []error
+	}
+	if got := errors.AsAll[multiError](err); len(got) != 3 {
+		t.Errorf("AsAll[multiError] found %d errors, want 3", len(got))
+	}
+	if got := errors.AsAll[*cyclicErr](err1); got != nil {
+		t.Errorf("AsAll with no match = %v, want nil", got)
+	}
+	if got := errors.AsAll[errorT](nil); got != nil {
+		t.Errorf("AsAll(nil) = %v, want nil", got)
+	}
+
+	// A cycle terminates.
+	c := &cyclicErr{}
+	c.next = c
+	if got := errors.AsAll[*cyclicErr](c); len(got) == 0 {
+		t.Errorf("AsAll on cyclic error found nothing")
+	}
+}
+
 type errorT struct{ s string }
 
 func (e errorT) Error() string { return fmt.Sprintf("errorT(%s)", e.s) }
@@ -226,6 +317,16 @@ func (e wrapped) Error() string { return e.msg }
 
 func (e wrapped) Unwrap() error { return e.err }
 
+type cyclicErr struct{ next error }
+
+func (e *cyclicErr) Error() string { return "cyclic" }
+func (e *cyclicErr) Unwrap() error { return e.next }
+
+type multiErr []error
+
+func (m multiErr) Error() string   { return "multiError" }
+func (m multiErr) Unwrap() []error { return []error(m) }
+
 type errorUncomparable struct {
 	f []string
 }
@@ -148,6 +148,7 @@ func (mu *fdMutex) rwlock(read bool) bool {
 		mutexMask = mutexWMask
 		mutexSema = &mu.wsema
 	}
+	iter := 0
 	for { // 死循环，直到成功抢占
 		old := atomic.LoadUint64(&mu.state)
 		if old&mutexClosed != 0 {
@@ -173,7 +174,12 @@ func (mu *fdMutex) rwlock(read bool) bool {
 			}
 			runtime_Semacquire(mutexSema) // note 锁已被抢占，这里估计是阻塞等待获取
 			// The signaller has subtracted mutexWait.
+			iter = 0
+			continue
 		}
+		// Lost the race with another state change; back off before retrying.
+		runtime_spinWait(iter)
+		iter++
 	}
 }
 
@@ -215,6 +221,7 @@ func (mu *fdMutex) rwunlock(read bool) bool {
 // Implemented in runtime package.
 func runtime_Semacquire(sema *uint32)
 func runtime_Semrelease(sema *uint32)
+func runtime_spinWait(iter int)
 
 // incref adds a reference to fd.
 // It returns an error when fd cannot be used.
@@ -220,3 +220,21 @@ func TestMutexStress(t *testing.T) {
 		t.Fatal("broken")
 	}
 }
+
+func BenchmarkMutexContended(b *testing.B) {
+	var mu FDMutex
+	b.RunParallel(func(pb *testing.PB) {
+		read := true
+		for pb.Next() {
+			if !mu.RWLock(read) {
+				b.Error("broken")
+				return
+			}
+			if mu.RWUnlock(read) {
+				b.Error("broken")
+				return
+			}
+			read = !read
+		}
+	})
+}
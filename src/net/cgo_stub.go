@@ -8,6 +8,9 @@ package net
 
 import "context"
 
+// cgoAvailable reports whether the cgo resolver can be used.
+const cgoAvailable = false
+
 type addrinfoErrno int
 
 func (eai addrinfoErrno) Error() string   { return "<nil>" }
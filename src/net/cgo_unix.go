@@ -27,6 +27,9 @@ import (
 	"unsafe"
 )
 
+// cgoAvailable reports whether the cgo resolver can be used.
+const cgoAvailable = true
+
 // An addrinfoErrno represents a getaddrinfo, getnameinfo-specific
 // error number. It's a signed number and a zero value is a non-error
 // by convention.
@@ -145,6 +145,11 @@ func (c *conf) hostLookupOrder(r *Resolver, hostname string) (ret hostLookupOrde
 			print("go package net: hostLookupOrder(", hostname, ") = ", ret.String(), "\n")
 		}()
 	}
+	if r.skipHostsFile() {
+		// Only DNS remains once the files step is dropped,
+		// and libc cannot be told to skip the hosts file.
+		return hostLookupDNS
+	}
 	fallbackOrder := hostLookupCgo
 	if c.netGo || r.preferGo() {
 		switch c.goos {
@@ -345,6 +345,19 @@ func TestConfHostLookupOrder(t *testing.T) {
 				{"localhost", "myhostname", hostLookupFilesDNS},
 			},
 		},
+		{
+			name:     "resolver-skiphostsfile",
+			resolver: &Resolver{SkipHostsFile: true},
+			c: &conf{
+				goos:   "linux",
+				resolv: defaultResolvConf,
+				nss:    nssStr("hosts: files dns"),
+			},
+			hostTests: []nssHostTest{
+				{"x.com", "myhostname", hostLookupDNS},
+				{"x.local", "myhostname", hostLookupDNS},
+			},
+		},
 	}
 
 	origGetHostname := getHostname
@@ -366,3 +379,23 @@ func TestConfHostLookupOrder(t *testing.T) {
 func TestSystemConf(t *testing.T) {
 	systemConf()
 }
+
+func TestResolverLookupOrder(t *testing.T) {
+	r := &Resolver{PreferGo: true}
+	want := systemConf().hostLookupOrder(r, "example.com")
+	if got := r.LookupOrder("example.com"); got != want.String() {
+		t.Errorf("LookupOrder with PreferGo = %q, want %q", got, want)
+	}
+	if got := r.LookupOrder("example.com"); got == hostLookupCgo.String() {
+		t.Errorf("LookupOrder with PreferGo = %q, want a Go resolver order", got)
+	}
+
+	got := DefaultResolver.LookupOrder("example.com")
+	valid := false
+	for _, name := range lookupOrderName {
+		valid = valid || got == name
+	}
+	if !valid {
+		t.Errorf("LookupOrder = %q, want one of %v", got, lookupOrderName)
+	}
+}
@@ -226,3 +226,16 @@ func (s byPref) sort() {
 type NS struct {
 	Host string
 }
+
+// A dnsSOA represents a DNS SOA record, which describes a DNS zone.
+// The intervals are in seconds.
+type dnsSOA struct {
+	Zone    string // name of the zone, the owner of the record
+	NS      string // primary name server of the zone
+	MBox    string // mailbox of the person responsible for the zone
+	Serial  uint32
+	Refresh uint32
+	Retry   uint32
+	Expire  uint32
+	MinTTL  uint32 // TTL of negative responses
+}
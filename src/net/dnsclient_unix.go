@@ -19,6 +19,7 @@ import (
 	"errors"
 	"internal/itoa"
 	"io"
+	"net/netip"
 	"os"
 	"runtime"
 	"sync"
@@ -51,7 +52,30 @@ var (
 	errServerTemporarilyMisbehaving = errors.New("server misbehaving")
 )
 
-func newRequest(q dnsmessage.Question) (id uint16, udpReq, tcpReq []byte, err error) {
+// dnsClientSubnetKey is the context key for a netip.Prefix that the DNS
+// client sends in the EDNS0 client-subnet option of its queries.
+type dnsClientSubnetKey struct{}
+
+// dnsOptionClientSubnet is the code of the EDNS0 client-subnet option.
+// RFC 7871.
+const dnsOptionClientSubnet = 8
+
+// clientSubnetOption returns the EDNS0 client-subnet option for the
+// masked prefix subnet.
+func clientSubnetOption(subnet netip.Prefix) dnsmessage.Option {
+	family := 1
+	if subnet.Addr().Is6() {
+		family = 2
+	}
+	bits := subnet.Bits()
+	data := []byte{byte(family >> 8), byte(family), byte(bits), 0} // the scope prefix length is 0 in queries
+	data = append(data, subnet.Addr().AsSlice()[:(bits+7)/8]...)
+	return dnsmessage.Option{Code: dnsOptionClientSubnet, Data: data}
+}
+
+// newRequest returns a request for q. If subnet is valid, the request
+// carries the EDNS0 client-subnet option for it.
+func newRequest(q dnsmessage.Question, subnet netip.Prefix) (id uint16, udpReq, tcpReq []byte, err error) {
 	id = uint16(randInt())
 	b := dnsmessage.NewBuilder(make([]byte, 2, 514), dnsmessage.Header{ID: id, RecursionDesired: true})
 	b.EnableCompression()
@@ -70,7 +94,11 @@ func newRequest(q dnsmessage.Question) (id uint16, udpReq, tcpReq []byte, err er
 	if err := rh.SetEDNS0(maxDNSPacketSize, dnsmessage.RCodeSuccess, false); err != nil {
 		return 0, nil, nil, err
 	}
-	if err := b.OPTResource(rh, dnsmessage.OPTResource{}); err != nil {
+	var opt dnsmessage.OPTResource
+	if subnet.IsValid() {
+		opt.Options = []dnsmessage.Option{clientSubnetOption(subnet)}
+	}
+	if err := b.OPTResource(rh, opt); err != nil {
 		return 0, nil, nil, err
 	}
 
@@ -160,7 +188,8 @@ func dnsStreamRoundTrip(c Conn, id uint16, query dnsmessage.Question, b []byte)
 // exchange sends a query on the connection and hopes for a response.
 func (r *Resolver) exchange(ctx context.Context, server string, q dnsmessage.Question, timeout time.Duration, useTCP bool) (dnsmessage.Parser, dnsmessage.Header, error) {
 	q.Class = dnsmessage.ClassINET
-	id, udpReq, tcpReq, err := newRequest(q)
+	subnet, _ := ctx.Value(dnsClientSubnetKey{}).(netip.Prefix)
+	id, udpReq, tcpReq, err := newRequest(q, subnet)
 	if err != nil {
 		return dnsmessage.Parser{}, dnsmessage.Header{}, errCannotMarshalDNSMessage
 	}
@@ -253,9 +282,29 @@ func skipToAnswer(p *dnsmessage.Parser, qtype dnsmessage.Type) error {
 	}
 }
 
+// queryContext returns a context for a query of type qtype,
+// bounded by the timeout configured in r.QueryTimeouts, if any.
+func (r *Resolver) queryContext(ctx context.Context, qtype dnsmessage.Type) (context.Context, context.CancelFunc) {
+	if r == nil || len(r.QueryTimeouts) == 0 {
+		return ctx, func() {}
+	}
+	name := qtype.String()
+	if len(name) > len("Type") && name[:len("Type")] == "Type" {
+		name = name[len("Type"):]
+	}
+	timeout, ok := r.QueryTimeouts[name]
+	if !ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
 // Do a lookup for a single name, which must be rooted
 // (otherwise answer will not find the answers).
 func (r *Resolver) tryOneName(ctx context.Context, cfg *dnsConfig, name string, qtype dnsmessage.Type) (dnsmessage.Parser, string, error) {
+	ctx, cancel := r.queryContext(ctx, qtype)
+	defer cancel()
+
 	var lastErr error
 	serverOffset := cfg.serverOffset()
 	sLen := uint32(len(cfg.servers))
@@ -405,6 +454,14 @@ func (conf *resolverConfig) tryUpdate(name string) {
 	conf.mu.Unlock()
 }
 
+// nameservers returns the servers of the current configuration.
+func (r *Resolver) nameservers() []string {
+	resolvConf.tryUpdate("/etc/resolv.conf")
+	resolvConf.mu.RLock()
+	defer resolvConf.mu.RUnlock()
+	return append([]string(nil), resolvConf.dnsConfig.servers...)
+}
+
 func (conf *resolverConfig) tryAcquireSema() bool {
 	select {
 	case conf.ch <- struct{}{}:
@@ -418,6 +475,11 @@ func (conf *resolverConfig) releaseSema() {
 	<-conf.ch
 }
 
+// lookup looks up name, trying each of the names in its search list in
+// turn, and returns the parser of the response positioned at its first
+// answer of type qtype. If the name does not exist or has no such
+// answers, the not found error is returned along with the parser of
+// the negative response, whose authority section may be inspected.
 func (r *Resolver) lookup(ctx context.Context, name string, qtype dnsmessage.Type) (dnsmessage.Parser, string, error) {
 	if !isDomainName(name) {
 		// We used to use "invalid domain name" as the error,
@@ -456,7 +518,7 @@ func (r *Resolver) lookup(ctx context.Context, name string, qtype dnsmessage.Typ
 		// just one is misleading. See also golang.org/issue/6324.
 		err.Name = name
 	}
-	return dnsmessage.Parser{}, "", err
+	return p, server, err
 }
 
 // avoidDNS reports whether this is a hostname for which we should not
@@ -593,6 +655,27 @@ func (r *Resolver) goLookupIP(ctx context.Context, network, host string) (addrs
 	return
 }
 
+// lookupIPSubnet is like lookupIPAddr but sends the EDNS0 client-subnet
+// option for subnet with each DNS query, so that servers that answer
+// according to the client's location, such as GeoDNS servers, answer as
+// for a client in subnet. Only the part of subnet's address covered by
+// its prefix length is sent.
+//
+// lookupIPSubnet always uses the Go resolver's DNS client and neither
+// consults the hosts file nor shares lookups with concurrent ones. Servers
+// that do not support the option ignore it; if a server rejects the query
+// as malformed instead, it is retried without the option.
+func (r *Resolver) lookupIPSubnet(ctx context.Context, network, host string, subnet netip.Prefix) ([]IPAddr, error) {
+	if !subnet.IsValid() {
+		return nil, &DNSError{Err: "invalid client subnet", Name: host}
+	}
+	addrs, _, err := r.goLookupIPCNAMEOrder(context.WithValue(ctx, dnsClientSubnetKey{}, subnet.Masked()), network, host, hostLookupDNS)
+	if dnsErr, ok := err.(*DNSError); ok && dnsErr.Err == errServerMisbehaving.Error() && ctx.Err() == nil {
+		addrs, _, err = r.goLookupIPCNAMEOrder(ctx, network, host, hostLookupDNS)
+	}
+	return addrs, err
+}
+
 func (r *Resolver) goLookupIPCNAMEOrder(ctx context.Context, network, name string, order hostLookupOrder) (addrs []IPAddr, cname dnsmessage.Name, err error) {
 	if order == hostLookupFilesDNS || order == hostLookupFiles {
 		addrs = goLookupIPFiles(name)
@@ -614,6 +697,8 @@ func (r *Resolver) goLookupIPCNAMEOrder(ctx context.Context, network, name strin
 		error
 	}
 	lane := make(chan result, 1)
+	ttl, _ := ctx.Value(dnsTTLKey{}).(*dnsTTL)
+	addrTTLs, _ := ctx.Value(dnsAddrTTLsKey{}).(*dnsAddrTTLs)
 	qtypes := []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA}
 	switch ipVersion(network) {
 	case '4':
@@ -704,6 +789,8 @@ func (r *Resolver) goLookupIPCNAMEOrder(ctx context.Context, network, name strin
 						break loop
 					}
 					addrs = append(addrs, IPAddr{IP: IP(a.A[:])})
+					ttl.observe(h.TTL)
+					addrTTLs.observe(IP(a.A[:]), h.TTL)
 
 				case dnsmessage.TypeAAAA:
 					aaaa, err := result.p.AAAAResource()
@@ -716,6 +803,8 @@ func (r *Resolver) goLookupIPCNAMEOrder(ctx context.Context, network, name strin
 						break loop
 					}
 					addrs = append(addrs, IPAddr{IP: IP(aaaa.AAAA[:])})
+					ttl.observe(h.TTL)
+					addrTTLs.observe(IP(aaaa.AAAA[:]), h.TTL)
 
 				default:
 					if err := result.p.SkipAnswer(); err != nil {
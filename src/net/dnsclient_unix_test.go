@@ -10,6 +10,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
 	"os"
 	"path"
 	"reflect"
@@ -1012,6 +1013,51 @@ func TestRetryTimeout(t *testing.T) {
 	}
 }
 
+func TestQueryTimeouts(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+
+	if err := conf.writeAndUpdate([]string{"nameserver 192.0.2.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	const txtTimeout = 100 * time.Millisecond
+	fake := fakeDNSServer{rh: func(_, s string, q dnsmessage.Message, deadline time.Time) (dnsmessage.Message, error) {
+		t.Log(s, q, deadline)
+
+		if deadline.IsZero() {
+			t.Error("zero deadline")
+		}
+		switch q.Questions[0].Type {
+		case dnsmessage.TypeTXT:
+			if d := time.Until(deadline); d > txtTimeout {
+				t.Errorf("TXT query deadline in %v, want at most %v", d, txtTimeout)
+			}
+		default:
+			if d := time.Until(deadline); d <= txtTimeout {
+				t.Errorf("%v query deadline in %v, want more than %v", q.Questions[0].Type, d, txtTimeout)
+			}
+		}
+		return mockTXTResponse(q), nil
+	}}
+	r := &Resolver{
+		PreferGo:      true,
+		Dial:          fake.DialContext,
+		QueryTimeouts: map[string]time.Duration{"TXT": txtTimeout},
+	}
+	if _, _, err := r.lookup(context.Background(), "www.golang.org", dnsmessage.TypeTXT); err != nil {
+		t.Fatal(err)
+	}
+	// The answer does not match the MX question; only the deadline
+	// seen by the server matters here.
+	r.lookup(context.Background(), "www.golang.org", dnsmessage.TypeMX)
+}
+
 func TestRotate(t *testing.T) {
 	// without rotation, always uses the first server
 	testRotate(t, false, []string{"192.0.2.1", "192.0.2.2"}, []string{"192.0.2.1:53", "192.0.2.1:53", "192.0.2.1:53"})
@@ -2216,3 +2262,453 @@ func TestDNSPacketSize(t *testing.T) {
 		t.Errorf("lookup failed: %v", err)
 	}
 }
+
+func TestCachingResolver(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+	if err := conf.writeAndUpdate([]string{"nameserver 8.8.8.8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var queries atomic.Int32 // A queries
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		r := dnsmessage.Message{
+			Header: dnsmessage.Header{
+				ID:                 q.ID,
+				Response:           true,
+				RecursionAvailable: true,
+			},
+			Questions: q.Questions,
+		}
+		if q.Questions[0].Type == dnsmessage.TypeA {
+			queries.Add(1)
+		}
+		if strings.HasPrefix(q.Questions[0].Name.String(), "missing.") {
+			r.Header.RCode = dnsmessage.RCodeNameError
+			return r, nil
+		}
+		if q.Questions[0].Type != dnsmessage.TypeA {
+			return r, nil
+		}
+		r.Answers = []dnsmessage.Resource{
+			{
+				Header: dnsmessage.ResourceHeader{
+					Name:   q.Questions[0].Name,
+					Type:   dnsmessage.TypeA,
+					Class:  dnsmessage.ClassINET,
+					TTL:    60,
+					Length: 4,
+				},
+				Body: &dnsmessage.AResource{
+					A: TestAddr,
+				},
+			},
+		}
+		return r, nil
+	}}
+
+	now := time.Now()
+	c := &CachingResolver{
+		Resolver:    &Resolver{PreferGo: true, Dial: fake.DialContext},
+		NegativeTTL: 10 * time.Second,
+		now:         func() time.Time { return now },
+	}
+	ctx := context.Background()
+	// lookup looks up host and checks whether it was sent to the server.
+	lookup := func(host string, wantQuery, wantErr bool) {
+		t.Helper()
+		before := queries.Load()
+		addrs, err := c.LookupIPAddr(ctx, host)
+		if wantErr != (err != nil) {
+			t.Fatalf("LookupIPAddr(%q) = %v, %v", host, addrs, err)
+		}
+		if err == nil && (len(addrs) != 1 || !addrs[0].IP.Equal(IP(TestAddr[:]))) {
+			t.Fatalf("LookupIPAddr(%q) = %v, want [%v]", host, addrs, IP(TestAddr[:]))
+		}
+		if queried := queries.Load() != before; queried != wantQuery {
+			t.Fatalf("LookupIPAddr(%q) queried the server: %v, want %v", host, queried, wantQuery)
+		}
+	}
+
+	lookup("cached.example", true, false)
+	lookup("cached.example", false, false)
+	if hosts, err := c.LookupHost(ctx, "cached.example"); err != nil || len(hosts) != 1 || hosts[0] != "192.0.2.1" {
+		t.Errorf("LookupHost = %v, %v, want [192.0.2.1], nil", hosts, err)
+	}
+	now = now.Add(59 * time.Second)
+	lookup("cached.example", false, false)
+	now = now.Add(time.Second) // the record's TTL has passed
+	lookup("cached.example", true, false)
+
+	lookup("missing.example", true, true)
+	lookup("missing.example", false, true)
+	now = now.Add(c.NegativeTTL)
+	lookup("missing.example", true, true)
+
+	// The least recently used host is evicted.
+	c.MaxEntries = 1
+	lookup("other.example", true, false)
+	lookup("other.example", false, false)
+	lookup("cached.example", true, false)
+}
+
+// A CachingResolver lookup that overlaps a plain lookup of the same
+// host must not share its result, which carries no TTL.
+func TestCachingResolverOverlappingLookup(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+	if err := conf.writeAndUpdate([]string{"nameserver 8.8.8.8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var queries atomic.Int32 // A queries
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		r := dnsmessage.Message{
+			Header: dnsmessage.Header{
+				ID:                 q.ID,
+				Response:           true,
+				RecursionAvailable: true,
+			},
+			Questions: q.Questions,
+		}
+		if q.Questions[0].Type != dnsmessage.TypeA {
+			return r, nil
+		}
+		switch queries.Add(1) {
+		case 1:
+			// Hold the plain lookup until the cached one has
+			// sent its own query, or give up after a while.
+			close(started)
+			select {
+			case <-release:
+			case <-time.After(2 * time.Second):
+			}
+		case 2:
+			close(release)
+		}
+		r.Answers = []dnsmessage.Resource{
+			{
+				Header: dnsmessage.ResourceHeader{
+					Name:   q.Questions[0].Name,
+					Type:   dnsmessage.TypeA,
+					Class:  dnsmessage.ClassINET,
+					TTL:    60,
+					Length: 4,
+				},
+				Body: &dnsmessage.AResource{
+					A: TestAddr,
+				},
+			},
+		}
+		return r, nil
+	}}
+
+	c := &CachingResolver{Resolver: &Resolver{PreferGo: true, Dial: fake.DialContext}}
+	ctx := context.Background()
+	done := make(chan error)
+	go func() {
+		_, err := c.Resolver.LookupIPAddr(ctx, "shared.example")
+		done <- err
+	}()
+	<-started
+	if _, err := c.LookupIPAddr(ctx, "shared.example"); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	before := queries.Load()
+	if _, err := c.LookupIPAddr(ctx, "shared.example"); err != nil {
+		t.Fatal(err)
+	}
+	if queries.Load() != before {
+		t.Errorf("LookupIPAddr queried the server; want the overlapping lookup to have been cached")
+	}
+}
+
+func TestLookupIPWithTTL(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+	if err := conf.writeAndUpdate([]string{"nameserver 8.8.8.8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		r := dnsmessage.Message{
+			Header: dnsmessage.Header{
+				ID:                 q.ID,
+				Response:           true,
+				RecursionAvailable: true,
+			},
+			Questions: q.Questions,
+		}
+		switch q.Questions[0].Type {
+		case dnsmessage.TypeA:
+			r.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{
+					Name:   q.Questions[0].Name,
+					Type:   dnsmessage.TypeA,
+					Class:  dnsmessage.ClassINET,
+					TTL:    60,
+					Length: 4,
+				},
+				Body: &dnsmessage.AResource{
+					A: TestAddr,
+				},
+			}}
+		case dnsmessage.TypeAAAA:
+			r.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{
+					Name:   q.Questions[0].Name,
+					Type:   dnsmessage.TypeAAAA,
+					Class:  dnsmessage.ClassINET,
+					TTL:    30,
+					Length: 16,
+				},
+				Body: &dnsmessage.AAAAResource{
+					AAAA: TestAddr6,
+				},
+			}}
+		}
+		return r, nil
+	}}
+
+	r := &Resolver{PreferGo: true, Dial: fake.DialContext}
+	addrs, err := r.lookupIPWithTTL(context.Background(), "ip", "ttl.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Duration{
+		IP(TestAddr[:]).String():  60 * time.Second,
+		IP(TestAddr6[:]).String(): 30 * time.Second,
+	}
+	if len(addrs) != len(want) {
+		t.Fatalf("lookupIPWithTTL = %v, want %d addresses", addrs, len(want))
+	}
+	for _, addr := range addrs {
+		if ttl, ok := want[addr.IP.String()]; !ok || !addr.HasTTL || addr.TTL != ttl {
+			t.Errorf("lookupIPWithTTL returned %v with TTL %v (%v), want TTL %v", addr.IP, addr.TTL, addr.HasTTL, ttl)
+		}
+	}
+
+	addrs, err = r.lookupIPWithTTL(context.Background(), "ip", "192.0.2.7")
+	if err != nil || len(addrs) != 1 || addrs[0].HasTTL {
+		t.Errorf("lookupIPWithTTL of a literal = %v, %v, want one address without TTL", addrs, err)
+	}
+}
+
+func TestLookupSOA(t *testing.T) {
+	apex := dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{
+			Name:  dnsmessage.MustNewName("example.com."),
+			Type:  dnsmessage.TypeSOA,
+			Class: dnsmessage.ClassINET,
+		},
+		Body: &dnsmessage.SOAResource{
+			NS:      dnsmessage.MustNewName("ns.example.com."),
+			MBox:    dnsmessage.MustNewName("hostmaster.example.com."),
+			Serial:  2023010101,
+			Refresh: 7200,
+			Retry:   3600,
+			Expire:  1209600,
+			MinTTL:  300,
+		},
+	}
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		r := dnsmessage.Message{
+			Header: dnsmessage.Header{
+				ID:                 q.ID,
+				Response:           true,
+				RecursionAvailable: true,
+			},
+			Questions: q.Questions,
+		}
+		switch q.Questions[0].Name.String() {
+		case "example.com.":
+			r.Answers = []dnsmessage.Resource{apex}
+		case "www.example.com.":
+			// The name exists but has no SOA record.
+			r.Authorities = []dnsmessage.Resource{apex}
+		case "missing.example.com.":
+			r.RCode = dnsmessage.RCodeNameError
+			r.Authorities = []dnsmessage.Resource{apex}
+		default:
+			r.RCode = dnsmessage.RCodeNameError
+		}
+		return r, nil
+	}}
+	r := &Resolver{PreferGo: true, Dial: fake.DialContext}
+
+	want := dnsSOA{
+		Zone:    "example.com.",
+		NS:      "ns.example.com.",
+		MBox:    "hostmaster.example.com.",
+		Serial:  2023010101,
+		Refresh: 7200,
+		Retry:   3600,
+		Expire:  1209600,
+		MinTTL:  300,
+	}
+	for _, name := range []string{"example.com.", "www.example.com.", "missing.example.com."} {
+		soa, err := r.lookupSOA(context.Background(), name)
+		if err != nil {
+			t.Errorf("lookupSOA(%q): %v", name, err)
+			continue
+		}
+		if *soa != want {
+			t.Errorf("lookupSOA(%q) = %+v, want %+v", name, *soa, want)
+		}
+	}
+
+	_, err := r.lookupSOA(context.Background(), "example.invalid.")
+	if de, ok := err.(*DNSError); !ok || !de.IsNotFound {
+		t.Errorf("lookupSOA of a name without a zone: err = %v, want not found", err)
+	}
+}
+
+func TestLookupIPSubnet(t *testing.T) {
+	defer dnsWaitGroup.Wait()
+
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+	if err := conf.writeAndUpdate([]string{"nameserver 8.8.8.8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server answers with the address of the client subnet it was
+	// sent, or rejects the option if reject is set.
+	var reject atomic.Bool
+	var mu sync.Mutex
+	var sent [][]byte
+	fake := fakeDNSServer{rh: func(_, _ string, q dnsmessage.Message, _ time.Time) (dnsmessage.Message, error) {
+		r := dnsmessage.Message{
+			Header: dnsmessage.Header{
+				ID:                 q.ID,
+				Response:           true,
+				RecursionAvailable: true,
+			},
+			Questions: q.Questions,
+		}
+		var ecs []byte
+		for _, res := range q.Additionals {
+			if opt, ok := res.Body.(*dnsmessage.OPTResource); ok {
+				for _, o := range opt.Options {
+					if o.Code == dnsOptionClientSubnet {
+						ecs = o.Data
+					}
+				}
+			}
+		}
+		mu.Lock()
+		sent = append(sent, ecs)
+		mu.Unlock()
+		if ecs != nil && reject.Load() {
+			r.RCode = dnsmessage.RCodeFormatError
+			return r, nil
+		}
+		a := [4]byte{192, 0, 2, 1}
+		if len(ecs) > 4 {
+			a = [4]byte{}
+			copy(a[:], ecs[4:])
+		}
+		if q.Questions[0].Type == dnsmessage.TypeA {
+			r.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{
+					Name:   q.Questions[0].Name,
+					Type:   dnsmessage.TypeA,
+					Class:  dnsmessage.ClassINET,
+					Length: 4,
+				},
+				Body: &dnsmessage.AResource{A: a},
+			}}
+		}
+		return r, nil
+	}}
+	r := &Resolver{PreferGo: true, Dial: fake.DialContext}
+
+	addrs, err := r.lookupIPSubnet(context.Background(), "ip4", "geo.example", netip.MustParsePrefix("198.51.100.77/24"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 1 || addrs[0].String() != "198.51.100.0" {
+		t.Errorf("lookupIPSubnet = %v, want [198.51.100.0]", addrs)
+	}
+	want := []byte{0, 1, 24, 0, 198, 51, 100}
+	if len(sent) != 1 || !reflect.DeepEqual(sent[0], want) {
+		t.Errorf("client-subnet options sent = %v, want [%v]", sent, want)
+	}
+
+	sent = nil
+	reject.Store(true)
+	addrs, err = r.lookupIPSubnet(context.Background(), "ip4", "geo.example", netip.MustParsePrefix("2001:db8::/32"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 1 || addrs[0].String() != "192.0.2.1" {
+		t.Errorf("lookupIPSubnet after rejected option = %v, want [192.0.2.1]", addrs)
+	}
+	// The query is retried without the option once every attempt with
+	// it has been rejected.
+	want = []byte{0, 2, 32, 0, 0x20, 0x01, 0x0d, 0xb8}
+	for i, ecs := range sent {
+		if last := i == len(sent)-1; last && ecs != nil || !last && !reflect.DeepEqual(ecs, want) {
+			t.Errorf("client-subnet options sent = %v, want %v until the last, which has none", sent, want)
+			break
+		}
+	}
+
+	if _, err := r.lookupIPSubnet(context.Background(), "ip4", "geo.example", netip.Prefix{}); err == nil {
+		t.Error("lookupIPSubnet with an invalid subnet succeeded")
+	}
+}
+
+func TestResolverNameservers(t *testing.T) {
+	conf, err := newResolvConfTest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conf.teardown()
+
+	if err := conf.writeAndUpdate([]string{"nameserver 8.8.8.8", "nameserver 2001:4860:4860::8888"}); err != nil {
+		t.Fatal(err)
+	}
+	var r *Resolver
+	want := []string{"8.8.8.8:53", "[2001:4860:4860::8888]:53"}
+	got := r.Nameservers()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Nameservers() = %v, want %v", got, want)
+	}
+	got[0] = "modified"
+	if got := r.Nameservers(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Nameservers() after modifying its result = %v, want %v", got, want)
+	}
+
+	// A change of configuration is seen.
+	if err := conf.writeAndUpdate([]string{"nameserver 192.0.2.1"}); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"192.0.2.1:53"}
+	if got := r.Nameservers(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Nameservers() after update = %v, want %v", got, want)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"internal/nettrace"
 	"internal/singleflight"
 	"net/netip"
+	"sort"
 	"sync"
+	"time"
 
 	"golang.org/x/net/dns/dnsmessage"
 )
@@ -112,6 +114,30 @@ func ipVersion(network string) byte {
 	return n
 }
 
+// An AddressPreference specifies which IP address family a Resolver
+// orders first in its results.
+type AddressPreference int
+
+const (
+	NoPreference AddressPreference = iota // keep the resolver's order
+	PreferV4                              // IPv4 addresses first
+	PreferV6                              // IPv6 addresses first
+)
+
+// sort stably reorders addrs so that addresses of the preferred
+// family come first.
+func (p AddressPreference) sort(addrs []IPAddr) {
+	if p != PreferV4 && p != PreferV6 {
+		return
+	}
+	preferred := func(a IPAddr) bool {
+		return (a.IP.To4() != nil) == (p == PreferV4)
+	}
+	sort.SliceStable(addrs, func(i, j int) bool {
+		return preferred(addrs[i]) && !preferred(addrs[j])
+	})
+}
+
 // DefaultResolver is the resolver used by the package-level Lookup
 // functions and by Dialers without a specified Resolver.
 var DefaultResolver = &Resolver{}
@@ -148,6 +174,30 @@ type Resolver struct {
 	// If nil, the default dialer is used.
 	Dial func(ctx context.Context, network, address string) (Conn, error)
 
+	// QueryTimeouts optionally specifies timeouts for the DNS queries
+	// made by Go's built-in resolver, keyed by record type name such as
+	// "A", "AAAA", "TXT" or "SRV". The timeout bounds each query for a
+	// single name, including retries across all configured servers.
+	// The deadline of the context passed to the lookup method is always
+	// respected, so a timeout longer than the remaining time has no
+	// effect. Record types without an entry use only the context.
+	QueryTimeouts map[string]time.Duration
+
+	// AddressPreference controls the order of the addresses returned
+	// by LookupIP, LookupIPAddr and LookupNetIP, and so
+	// the order in which a Dialer using this Resolver tries them.
+	// With PreferV4 or PreferV6, addresses of the preferred family
+	// come first; the relative order of addresses within each family
+	// is preserved. The zero value, NoPreference, keeps the order
+	// provided by the underlying resolver.
+	AddressPreference AddressPreference
+
+	// SkipHostsFile causes host lookups to ignore the local hosts
+	// file, such as /etc/hosts, and to resolve names using only DNS.
+	// Because the C library resolver always consults the hosts file,
+	// setting SkipHostsFile also selects Go's built-in resolver.
+	SkipHostsFile bool
+
 	// lookupGroup merges LookupIPAddr calls together for lookups for the same
 	// host. The lookupGroup key is the LookupIPAddr.host argument.
 	// The return values are ([]IPAddr, error).
@@ -157,8 +207,16 @@ type Resolver struct {
 	// TODO(bradfitz): Timeout time.Duration?
 }
 
-func (r *Resolver) preferGo() bool     { return r != nil && r.PreferGo }
-func (r *Resolver) strictErrors() bool { return r != nil && r.StrictErrors }
+func (r *Resolver) preferGo() bool      { return r != nil && r.PreferGo }
+func (r *Resolver) strictErrors() bool  { return r != nil && r.StrictErrors }
+func (r *Resolver) skipHostsFile() bool { return r != nil && r.SkipHostsFile }
+
+func (r *Resolver) addressPreference() AddressPreference {
+	if r == nil {
+		return NoPreference
+	}
+	return r.AddressPreference
+}
 
 func (r *Resolver) getLookupGroup() *singleflight.Group {
 	if r == nil {
@@ -176,6 +234,17 @@ func LookupHost(host string) (addrs []string, err error) {
 	return DefaultResolver.LookupHost(context.Background(), host)
 }
 
+// LookupOrder returns a description of the strategy that LookupHost,
+// LookupIP and related methods of r would use to resolve host, for
+// debugging. The result is a comma-separated list of the sources
+// consulted in order, where "files" means the hosts file (such as
+// /etc/hosts) and "dns" means Go's built-in DNS resolver, or "cgo" if
+// resolution is delegated to the operating system's resolver.
+// On platforms without host name resolution it returns "".
+func (r *Resolver) LookupOrder(host string) string {
+	return r.lookupOrder(host)
+}
+
 // LookupHost looks up the given host using the local resolver.
 // It returns a slice of that host's addresses.
 func (r *Resolver) LookupHost(ctx context.Context, host string) (addrs []string, err error) {
@@ -314,9 +383,18 @@ func (r *Resolver) lookupIPAddr(ctx context.Context, network, host string) ([]IP
 	// only the values in context. See Issue 28600.
 	lookupGroupCtx, lookupGroupCancel := context.WithCancel(withUnexpiredValuesPreserved(ctx))
 
+	// A lookup that records TTLs through ctx must not share the
+	// result of a lookup started with another context, which would
+	// leave them unrecorded.
+	group := r.getLookupGroup()
+	if ctx.Value(dnsTTLKey{}) != nil || ctx.Value(dnsAddrTTLsKey{}) != nil {
+		group = new(singleflight.Group)
+	}
+
+	pref := r.addressPreference()
 	lookupKey := network + "\000" + host
 	dnsWaitGroup.Add(1)
-	ch, called := r.getLookupGroup().DoChan(lookupKey, func() (any, error) {
+	ch, called := group.DoChan(lookupKey, func() (any, error) {
 		defer dnsWaitGroup.Done()
 		return testHookLookupIP(lookupGroupCtx, resolverFunc, network, host)
 	})
@@ -333,7 +411,7 @@ func (r *Resolver) lookupIPAddr(ctx context.Context, network, host string) ([]IP
 		// let the lookup continue uncanceled, and let later
 		// lookups with the same key share the result.
 		// See issues 8602, 20703, 22724.
-		if r.getLookupGroup().ForgetUnshared(lookupKey) {
+		if group.ForgetUnshared(lookupKey) {
 			lookupGroupCancel()
 		} else {
 			go func() {
@@ -373,10 +451,98 @@ func (r *Resolver) lookupIPAddr(ctx context.Context, network, host string) ([]IP
 			addrs, _ := r.Val.([]IPAddr)
 			trace.DNSDone(ipAddrsEface(addrs), r.Shared, err)
 		}
-		return lookupIPReturn(r.Val, err, r.Shared)
+		addrs, err := lookupIPReturn(r.Val, err, r.Shared)
+		if err == nil {
+			pref.sort(addrs)
+		}
+		return addrs, err
 	}
 }
 
+// lookupIPHappyEyeballs looks up the IPv6 and IPv4 addresses of host
+// concurrently and returns them interleaved by family, starting with
+// IPv6 (v6, v4, v6, v4, ...), so that a dialer can race connection
+// attempts as described in RFC 8305. Once one family runs out, the
+// remaining addresses of the other follow in order. If only one of the
+// lookups fails, its error is ignored; if both fail, the IPv4 lookup's
+// error is returned.
+func (r *Resolver) lookupIPHappyEyeballs(ctx context.Context, host string) ([]IPAddr, error) {
+	if ip, zone := parseIPZone(host); ip != nil {
+		return []IPAddr{{IP: ip, Zone: zone}}, nil
+	}
+	type result struct {
+		addrs []IPAddr
+		err   error
+	}
+	// only returns the addresses in addrs for which keep returns true.
+	only := func(addrs []IPAddr, keep func(IPAddr) bool) []IPAddr {
+		var kept []IPAddr
+		for _, a := range addrs {
+			if keep(a) {
+				kept = append(kept, a)
+			}
+		}
+		return kept
+	}
+	ch6 := make(chan result, 1)
+	go func() {
+		addrs, err := r.lookupIPAddr(ctx, "ip6", host)
+		ch6 <- result{only(addrs, ipv6only), err}
+	}()
+	addrs4, err4 := r.lookupIPAddr(ctx, "ip4", host)
+	addrs4 = only(addrs4, ipv4only)
+	r6 := <-ch6
+	if err4 != nil && r6.err != nil {
+		return nil, err4
+	}
+
+	addrs := make([]IPAddr, 0, len(r6.addrs)+len(addrs4))
+	for i := 0; i < len(r6.addrs) || i < len(addrs4); i++ {
+		if i < len(r6.addrs) {
+			addrs = append(addrs, r6.addrs[i])
+		}
+		if i < len(addrs4) {
+			addrs = append(addrs, addrs4[i])
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, &DNSError{Err: errNoSuchHost.Error(), Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+// An ipAddrTTL is an IP address together with the TTL of the DNS
+// record it was found in.
+type ipAddrTTL struct {
+	IPAddr
+
+	// TTL is how long the address may be cached, as given by its DNS
+	// record. It is only valid if HasTTL is set, which it is not if
+	// the address did not come from a DNS record, for example because
+	// it was found in the hosts file or by the cgo resolver.
+	TTL    time.Duration
+	HasTTL bool
+}
+
+// lookupIPWithTTL is like lookupIPAddr but also reports the TTL of the
+// DNS record each address was found in.
+func (r *Resolver) lookupIPWithTTL(ctx context.Context, network, host string) ([]ipAddrTTL, error) {
+	ttls := new(dnsAddrTTLs)
+	addrs, err := r.lookupIPAddr(context.WithValue(ctx, dnsAddrTTLsKey{}, ttls), network, host)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]ipAddrTTL, len(addrs))
+	for i, addr := range addrs {
+		res[i].IPAddr = addr
+		if secs, ok := ttls.get(addr.IP); ok {
+			res[i].TTL = time.Duration(secs) * time.Second
+			res[i].HasTTL = true
+		}
+	}
+	return res, nil
+}
+
 // lookupIPReturn turns the return values from singleflight.Do into
 // the return values from LookupIP.
 func lookupIPReturn(addrsi any, err error, shared bool) ([]IPAddr, error) {
@@ -611,6 +777,20 @@ func (r *Resolver) LookupNS(ctx context.Context, name string) ([]*NS, error) {
 	return filteredNS, nil
 }
 
+// Nameservers returns the addresses, in host:port form, of the DNS
+// servers that the Go resolver queries, in the order in which it tries
+// them by default. They come from /etc/resolv.conf or its equivalent on
+// the system, which is reread if it may have changed, as it is before a
+// lookup. If r.Dial is set, these are the addresses passed to it, and
+// the servers reached depend on what it dials.
+//
+// The servers are those used by the Go resolver, even when lookups use
+// the system's resolver instead. On js/wasm, which has no Go resolver,
+// Nameservers returns nil.
+func (r *Resolver) Nameservers() []string {
+	return r.nameservers()
+}
+
 // LookupTXT returns the DNS TXT records for the given domain name.
 //
 // LookupTXT uses context.Background internally; to specify the context, use
@@ -836,6 +1016,92 @@ func (r *Resolver) goLookupNS(ctx context.Context, name string) ([]*NS, error) {
 	return nss, nil
 }
 
+// goLookupSOA returns the SOA record of the zone containing name. Only
+// the apex of a zone has an SOA record; for names below it, including
+// names that do not exist, goLookupSOA returns the SOA record that the
+// server includes in the authority section of its negative response.
+func (r *Resolver) goLookupSOA(ctx context.Context, name string) (*dnsSOA, error) {
+	p, server, err := r.lookup(ctx, name, dnsmessage.TypeSOA)
+	if err != nil {
+		if de, ok := err.(*DNSError); ok && de.IsNotFound {
+			if soa := authoritySOA(&p); soa != nil {
+				return soa, nil
+			}
+		}
+		return nil, err
+	}
+	for {
+		h, err := p.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			return nil, &DNSError{Err: errNoSuchHost.Error(), Name: name, Server: server, IsNotFound: true}
+		}
+		if err != nil {
+			return nil, &DNSError{
+				Err:    "cannot unmarshal DNS message",
+				Name:   name,
+				Server: server,
+			}
+		}
+		if h.Type != dnsmessage.TypeSOA {
+			if err := p.SkipAnswer(); err != nil {
+				return nil, &DNSError{
+					Err:    "cannot unmarshal DNS message",
+					Name:   name,
+					Server: server,
+				}
+			}
+			continue
+		}
+		soa, err := p.SOAResource()
+		if err != nil {
+			return nil, &DNSError{
+				Err:    "cannot unmarshal DNS message",
+				Name:   name,
+				Server: server,
+			}
+		}
+		return newDNSSOA(h, soa), nil
+	}
+}
+
+// authoritySOA returns the SOA record in the authority section of the
+// negative response p, or nil if there is none.
+func authoritySOA(p *dnsmessage.Parser) *dnsSOA {
+	if err := p.SkipAllAnswers(); err != nil {
+		return nil
+	}
+	for {
+		h, err := p.AuthorityHeader()
+		if err != nil {
+			return nil
+		}
+		if h.Type != dnsmessage.TypeSOA {
+			if err := p.SkipAuthority(); err != nil {
+				return nil
+			}
+			continue
+		}
+		soa, err := p.SOAResource()
+		if err != nil {
+			return nil
+		}
+		return newDNSSOA(h, soa)
+	}
+}
+
+func newDNSSOA(h dnsmessage.ResourceHeader, soa dnsmessage.SOAResource) *dnsSOA {
+	return &dnsSOA{
+		Zone:    h.Name.String(),
+		NS:      soa.NS.String(),
+		MBox:    soa.MBox.String(),
+		Serial:  soa.Serial,
+		Refresh: soa.Refresh,
+		Retry:   soa.Retry,
+		Expire:  soa.Expire,
+		MinTTL:  soa.MinTTL,
+	}
+}
+
 // goLookupTXT returns the TXT records from name.
 func (r *Resolver) goLookupTXT(ctx context.Context, name string) ([]string, error) {
 	p, server, err := r.lookup(ctx, name, dnsmessage.TypeTXT)
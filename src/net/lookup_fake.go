@@ -19,6 +19,10 @@ func (*Resolver) lookupHost(ctx context.Context, host string) (addrs []string, e
 	return nil, syscall.ENOPROTOOPT
 }
 
+func (*Resolver) lookupOrder(host string) string {
+	return ""
+}
+
 func (*Resolver) lookupIP(ctx context.Context, network, host string) (addrs []IPAddr, err error) {
 	return nil, syscall.ENOPROTOOPT
 }
@@ -43,6 +47,14 @@ func (*Resolver) lookupNS(ctx context.Context, name string) (nss []*NS, err erro
 	return nil, syscall.ENOPROTOOPT
 }
 
+func (*Resolver) nameservers() []string {
+	return nil
+}
+
+func (*Resolver) lookupSOA(ctx context.Context, name string) (*dnsSOA, error) {
+	return nil, syscall.ENOPROTOOPT
+}
+
 func (*Resolver) lookupTXT(ctx context.Context, name string) (txts []string, err error) {
 	return nil, syscall.ENOPROTOOPT
 }
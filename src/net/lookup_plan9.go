@@ -196,6 +196,10 @@ func (r *Resolver) preferGoOverPlan9() bool {
 	return order != hostLookupCgo && r != nil && r.Dial != nil
 }
 
+func (r *Resolver) lookupOrder(host string) string {
+	return systemConf().hostLookupOrder(r, host).String()
+}
+
 func (r *Resolver) lookupIP(ctx context.Context, network, host string) (addrs []IPAddr, err error) {
 	if r.preferGoOverPlan9() {
 		return r.goLookupIP(ctx, network, host)
@@ -334,6 +338,11 @@ func (r *Resolver) lookupNS(ctx context.Context, name string) (ns []*NS, err err
 	return
 }
 
+// lookupSOA always uses the Go resolver.
+func (r *Resolver) lookupSOA(ctx context.Context, name string) (*dnsSOA, error) {
+	return r.goLookupSOA(ctx, name)
+}
+
 func (r *Resolver) lookupTXT(ctx context.Context, name string) (txt []string, err error) {
 	if r.preferGoOverPlan9() {
 		return r.goLookupTXT(ctx, name)
@@ -1387,3 +1387,83 @@ func TestDNSTimeout(t *testing.T) {
 	checkErr(err2)
 	cancel()
 }
+
+func TestResolverAddressPreference(t *testing.T) {
+	origTestHookLookupIP := testHookLookupIP
+	defer func() { testHookLookupIP = origTestHookLookupIP }()
+	testHookLookupIP = func(ctx context.Context, fn func(context.Context, string, string) ([]IPAddr, error), network, host string) ([]IPAddr, error) {
+		return []IPAddr{
+			{IP: ParseIP("2001:db8::1")},
+			{IP: IPv4(192, 0, 2, 1)},
+			{IP: ParseIP("2001:db8::2")},
+			{IP: IPv4(192, 0, 2, 2)},
+		}, nil
+	}
+
+	tests := []struct {
+		pref AddressPreference
+		want []string
+	}{
+		{NoPreference, []string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "192.0.2.2"}},
+		{PreferV4, []string{"192.0.2.1", "192.0.2.2", "2001:db8::1", "2001:db8::2"}},
+		{PreferV6, []string{"2001:db8::1", "2001:db8::2", "192.0.2.1", "192.0.2.2"}},
+	}
+	for _, tt := range tests {
+		r := &Resolver{AddressPreference: tt.pref}
+		addrs, err := r.LookupIPAddr(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("LookupIPAddr with preference %d: %v", tt.pref, err)
+		}
+		var got []string
+		for _, a := range addrs {
+			got = append(got, a.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LookupIPAddr with preference %d = %v, want %v", tt.pref, got, tt.want)
+		}
+	}
+}
+
+func TestLookupIPHappyEyeballs(t *testing.T) {
+	origTestHookLookupIP := testHookLookupIP
+	defer func() { testHookLookupIP = origTestHookLookupIP }()
+	testHookLookupIP = func(ctx context.Context, fn func(context.Context, string, string) ([]IPAddr, error), network, host string) ([]IPAddr, error) {
+		switch host + "/" + network {
+		case "dual.example/ip4", "v4only.example/ip4":
+			return []IPAddr{{IP: IPv4(192, 0, 2, 1)}, {IP: IPv4(192, 0, 2, 2)}, {IP: IPv4(192, 0, 2, 3)}}, nil
+		case "dual.example/ip6":
+			return []IPAddr{{IP: ParseIP("2001:db8::1")}, {IP: ParseIP("2001:db8::2")}}, nil
+		}
+		return nil, &DNSError{Err: errNoSuchHost.Error(), Name: host, IsNotFound: true}
+	}
+
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{"dual.example", []string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "192.0.2.2", "192.0.2.3"}},
+		{"v4only.example", []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"}},
+		{"192.0.2.9", []string{"192.0.2.9"}},
+		{"missing.example", nil},
+	}
+	for _, tt := range tests {
+		addrs, err := DefaultResolver.lookupIPHappyEyeballs(context.Background(), tt.host)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("lookupIPHappyEyeballs(%q) = %v, want error", tt.host, addrs)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("lookupIPHappyEyeballs(%q): %v", tt.host, err)
+			continue
+		}
+		var got []string
+		for _, a := range addrs {
+			got = append(got, a.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lookupIPHappyEyeballs(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
@@ -82,6 +82,15 @@ func (r *Resolver) lookupHost(ctx context.Context, host string) (addrs []string,
 	return r.goLookupHostOrder(ctx, host, order)
 }
 
+func (r *Resolver) lookupOrder(host string) string {
+	order := systemConf().hostLookupOrder(r, host)
+	if order == hostLookupCgo && !cgoAvailable {
+		// lookupHost and lookupIP fall back to Go's resolver.
+		order = hostLookupFilesDNS
+	}
+	return order.String()
+}
+
 func (r *Resolver) lookupIP(ctx context.Context, network, host string) (addrs []IPAddr, err error) {
 	if r.preferGo() {
 		return r.goLookupIP(ctx, network, host)
@@ -135,6 +144,10 @@ func (r *Resolver) lookupNS(ctx context.Context, name string) ([]*NS, error) {
 	return r.goLookupNS(ctx, name)
 }
 
+func (r *Resolver) lookupSOA(ctx context.Context, name string) (*dnsSOA, error) {
+	return r.goLookupSOA(ctx, name)
+}
+
 func (r *Resolver) lookupTXT(ctx context.Context, name string) ([]string, error) {
 	return r.goLookupTXT(ctx, name)
 }
@@ -82,6 +82,10 @@ func (r *Resolver) lookupHost(ctx context.Context, name string) ([]string, error
 	return addrs, nil
 }
 
+func (r *Resolver) lookupOrder(host string) string {
+	return systemConf().hostLookupOrder(r, host).String()
+}
+
 // preferGoOverWindows reports whether the resolver should use the
 // pure Go implementation rather than making win32 calls to ask the
 // kernel for its answer.
@@ -327,6 +331,12 @@ func (r *Resolver) lookupNS(ctx context.Context, name string) ([]*NS, error) {
 	return nss, nil
 }
 
+// lookupSOA always uses the Go resolver: the syscall package does not
+// describe the SOA records returned by DnsQuery.
+func (r *Resolver) lookupSOA(ctx context.Context, name string) (*dnsSOA, error) {
+	return r.goLookupSOA(ctx, name)
+}
+
 func (r *Resolver) lookupTXT(ctx context.Context, name string) ([]string, error) {
 	if r.preferGoOverWindows() {
 		return r.goLookupTXT(ctx, name)
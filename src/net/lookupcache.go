@@ -0,0 +1,283 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import (
+	"context"
+	"internal/singleflight"
+	"sync"
+	"time"
+)
+
+// defaultCachingResolverEntries is the number of host names cached by a
+// CachingResolver whose MaxEntries is zero.
+const defaultCachingResolverEntries = 1024
+
+// A CachingResolver is a Resolver that caches the results of its
+// LookupHost and LookupIPAddr methods.
+//
+// Successful lookups are cached for the smallest TTL of the DNS records
+// they returned. Results obtained without a TTL, for example from the
+// hosts file or from the cgo resolver, are cached for DefaultTTL.
+// Lookups that fail because the host does not exist are cached for
+// NegativeTTL; other errors are not cached. The least recently used
+// host is dropped when the cache is full.
+//
+// Concurrent lookups of a host that is not in the cache share a single
+// lookup. That lookup continues even if the context of the caller that
+// started it is canceled, so that its result can be cached.
+//
+// The other methods of the embedded Resolver, including LookupIP and
+// LookupNetIP, are not cached.
+//
+// A CachingResolver must not be copied after first use.
+type CachingResolver struct {
+	// Resolver performs the lookups. A nil Resolver is equivalent
+	// to a zero Resolver.
+	*Resolver
+
+	// MaxEntries is the maximum number of hosts to cache.
+	// If zero, a default of 1024 is used.
+	MaxEntries int
+
+	// DefaultTTL is how long to cache the results of lookups that do
+	// not report a TTL. If zero, such results are not cached.
+	DefaultTTL time.Duration
+
+	// NegativeTTL is how long to cache that a host does not exist.
+	// If zero, such errors are not cached.
+	NegativeTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*hostCacheEntry
+	lru     hostCacheEntry // sentinel of the entries, most recently used first
+	group   singleflight.Group
+
+	now func() time.Time // for testing; nil means time.Now
+}
+
+// A hostCacheEntry is the cached result of looking up a host.
+type hostCacheEntry struct {
+	host    string
+	addrs   []IPAddr
+	err     error
+	expires time.Time
+
+	prev, next *hostCacheEntry
+}
+
+// LookupHost looks up the given host using the cache and, on a miss,
+// the embedded Resolver. It returns a slice of that host's addresses.
+func (c *CachingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	// Make sure that no matter what we do later, host=="" is rejected.
+	if host == "" {
+		return nil, &DNSError{Err: errNoSuchHost.Error(), Name: host, IsNotFound: true}
+	}
+	if ip, _ := parseIPZone(host); ip != nil {
+		return []string{host}, nil
+	}
+	addrs, err := c.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, len(addrs))
+	for i, addr := range addrs {
+		hosts[i] = addr.String()
+	}
+	return hosts, nil
+}
+
+// LookupIPAddr looks up host using the cache and, on a miss, the
+// embedded Resolver. It returns a slice of that host's IPv4 and IPv6
+// addresses.
+func (c *CachingResolver) LookupIPAddr(ctx context.Context, host string) ([]IPAddr, error) {
+	if host == "" {
+		return nil, &DNSError{Err: errNoSuchHost.Error(), Name: host, IsNotFound: true}
+	}
+	if ip, zone := parseIPZone(host); ip != nil {
+		return []IPAddr{{IP: ip, Zone: zone}}, nil
+	}
+	if e := c.get(host); e != nil {
+		return lookupIPReturn(e.addrs, e.err, true)
+	}
+
+	ch, _ := c.group.DoChan(host, func() (any, error) {
+		return c.lookup(ctx, host)
+	})
+	select {
+	case <-ctx.Done():
+		ctxErr := ctx.Err()
+		return nil, &DNSError{
+			Err:       mapErr(ctxErr).Error(),
+			Name:      host,
+			IsTimeout: ctxErr == context.DeadlineExceeded,
+		}
+	case r := <-ch:
+		// The result is shared with the cache, so always copy it.
+		return lookupIPReturn(r.Val, r.Err, true)
+	}
+}
+
+// lookup looks up host with the embedded Resolver and caches the result.
+func (c *CachingResolver) lookup(ctx context.Context, host string) ([]IPAddr, error) {
+	// Like Resolver.lookupIPAddr, keep only the values of ctx so
+	// that its cancellation does not affect the callers sharing
+	// this lookup.
+	ttl := new(dnsTTL)
+	lookupCtx := context.WithValue(withUnexpiredValuesPreserved(ctx), dnsTTLKey{}, ttl)
+	addrs, err := c.Resolver.LookupIPAddr(lookupCtx, host)
+
+	var d time.Duration
+	if err == nil {
+		if secs, ok := ttl.get(); ok {
+			d = time.Duration(secs) * time.Second
+		} else {
+			d = c.DefaultTTL
+		}
+	} else if derr, ok := err.(*DNSError); ok && derr.IsNotFound {
+		d = c.NegativeTTL
+	}
+	if d > 0 {
+		c.add(host, addrs, err, c.timeNow().Add(d))
+	}
+	return addrs, err
+}
+
+func (c *CachingResolver) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// get returns the unexpired cache entry for host, or nil if there is
+// none. The addrs and err fields of the entry are never modified.
+func (c *CachingResolver) get(host string) *hostCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entries[host]
+	if e == nil {
+		return nil
+	}
+	if !c.timeNow().Before(e.expires) {
+		c.remove(e)
+		return nil
+	}
+	c.unlink(e)
+	c.pushFront(e)
+	return e
+}
+
+// add caches the result of looking up host until expires, evicting the
+// least recently used entries if the cache is full.
+func (c *CachingResolver) add(host string, addrs []IPAddr, err error, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*hostCacheEntry)
+		c.lru.prev, c.lru.next = &c.lru, &c.lru
+	}
+	if e := c.entries[host]; e != nil {
+		c.remove(e)
+	}
+	e := &hostCacheEntry{host: host, addrs: addrs, err: err, expires: expires}
+	c.entries[host] = e
+	c.pushFront(e)
+
+	limit := c.MaxEntries
+	if limit <= 0 {
+		limit = defaultCachingResolverEntries
+	}
+	for len(c.entries) > limit {
+		c.remove(c.lru.prev)
+	}
+}
+
+// remove drops e from the cache. c.mu must be held.
+func (c *CachingResolver) remove(e *hostCacheEntry) {
+	c.unlink(e)
+	delete(c.entries, e.host)
+}
+
+// unlink removes e from the LRU list. c.mu must be held.
+func (c *CachingResolver) unlink(e *hostCacheEntry) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+}
+
+// pushFront inserts e at the front of the LRU list. c.mu must be held.
+func (c *CachingResolver) pushFront(e *hostCacheEntry) {
+	e.prev = &c.lru
+	e.next = c.lru.next
+	c.lru.next.prev = e
+	c.lru.next = e
+}
+
+// dnsTTLKey is the context key for a *dnsTTL that the DNS client fills
+// with the TTL of the address records it returns.
+type dnsTTLKey struct{}
+
+// A dnsTTL records the smallest TTL of a set of DNS records.
+type dnsTTL struct {
+	mu  sync.Mutex
+	min uint32 // in seconds
+	ok  bool
+}
+
+// observe records a TTL, in seconds. It is a no-op on a nil *dnsTTL.
+func (t *dnsTTL) observe(ttl uint32) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if !t.ok || ttl < t.min {
+		t.min, t.ok = ttl, true
+	}
+	t.mu.Unlock()
+}
+
+// get returns the smallest TTL observed and whether there was any.
+func (t *dnsTTL) get() (uint32, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.min, t.ok
+}
+
+// dnsAddrTTLsKey is the context key for a *dnsAddrTTLs that the DNS
+// client fills with the TTL of each address record it returns.
+type dnsAddrTTLsKey struct{}
+
+// A dnsAddrTTLs records the TTLs of DNS address records by address.
+type dnsAddrTTLs struct {
+	mu   sync.Mutex
+	ttls map[string]uint32 // in seconds, by 16-byte IP
+}
+
+// observe records the TTL, in seconds, of a record for ip, keeping the
+// smallest TTL if ip was seen before. It is a no-op on a nil
+// *dnsAddrTTLs.
+func (t *dnsAddrTTLs) observe(ip IP, ttl uint32) {
+	if t == nil {
+		return
+	}
+	key := string(ip.To16())
+	t.mu.Lock()
+	if old, ok := t.ttls[key]; !ok || ttl < old {
+		if t.ttls == nil {
+			t.ttls = make(map[string]uint32)
+		}
+		t.ttls[key] = ttl
+	}
+	t.mu.Unlock()
+}
+
+// get returns the TTL recorded for ip and whether there was any.
+func (t *dnsAddrTTLs) get(ip IP) (uint32, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ttl, ok := t.ttls[string(ip.To16())]
+	return ttl, ok
+}
@@ -4,6 +4,12 @@
 
 package textproto
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // 本质上就是个map啦，为了标准化或者说定制化，包装了一层type MIMEHeader
 // 然后还有一个CanonicalMIMEHeaderKey()来标准化key，其它就是增删改查了
 
@@ -37,6 +43,35 @@ func (h MIMEHeader) Add(key, value string) {
 	h[key] = append(h[key], value)
 }
 
+// AddValid is like Add but first checks that key is a valid
+// header field name as reported by ValidHeaderFieldName.
+// If it is not, AddValid leaves h unchanged and returns an error.
+// Servers adding headers whose names come from untrusted input
+// should use AddValid to prevent header injection.
+func (h MIMEHeader) AddValid(key, value string) error {
+	if !ValidHeaderFieldName(key) {
+		return ProtocolError(fmt.Sprintf("invalid header field name: %q", key))
+	}
+	h.Add(key, value)
+	return nil
+}
+
+// ValidHeaderFieldName reports whether key is a valid header field
+// name: a non-empty token as defined by RFC 7230, section 3.2.6.
+// Names containing control characters, spaces, or separators such
+// as ':' are not valid.
+func ValidHeaderFieldName(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if !validHeaderFieldByte(key[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // Set sets the header entries associated with key to
 // the single element value. It replaces any existing
 // values associated with key.
@@ -73,7 +108,296 @@ func (h MIMEHeader) Values(key string) []string {
 	return h[CanonicalMIMEHeaderKey(key)]
 }
 
+// GetJoined returns all values associated with the given key joined
+// by ", ", as multiple header fields with the same name may be combined
+// into one (RFC 7230, section 3.2.2). It is case insensitive;
+// CanonicalMIMEHeaderKey is used to canonicalize the provided key.
+// If there are no values associated with the key, GetJoined returns "".
+func (h MIMEHeader) GetJoined(key string) string {
+	return strings.Join(h.Values(key), ", ")
+}
+
+// SetJoined sets the header entries associated with key to the elements
+// of the comma-separated list joined, replacing any existing values.
+// Commas inside quoted strings do not separate elements. Leading and
+// trailing space is trimmed from each element, and empty elements are
+// dropped. If joined has no elements, SetJoined deletes key.
+func (h MIMEHeader) SetJoined(key, joined string) {
+	key = CanonicalMIMEHeaderKey(key)
+	vv := splitHeaderList(joined)
+	if len(vv) == 0 {
+		delete(h, key)
+		return
+	}
+	h[key] = vv
+}
+
+// GetList returns the elements of the values associated with the given
+// key. It is case insensitive; CanonicalMIMEHeaderKey is used to
+// canonicalize the provided key.
+//
+// If foldable is true, the header is treated as a comma-separated list,
+// whose fields may be combined into one or split apart without changing
+// its meaning (RFC 7230, section 3.2.2). Each value is then split at
+// commas outside quoted strings, as by SetJoined, and the resulting
+// elements are returned in order.
+//
+// If foldable is false, GetList returns the values unchanged, like
+// Values. Use this for headers such as Set-Cookie whose values may
+// themselves contain commas and so must never be split or joined.
+func (h MIMEHeader) GetList(key string, foldable bool) []string {
+	vv := h.Values(key)
+	if !foldable {
+		return vv
+	}
+	var list []string
+	for _, v := range vv {
+		list = append(list, splitHeaderList(v)...)
+	}
+	return list
+}
+
+// splitHeaderList splits a comma-separated header field value into its
+// non-empty elements. Commas in quoted strings, which may contain
+// backslash-escaped quotes, do not split.
+func splitHeaderList(s string) []string {
+	var vv []string
+	quoted, escaped := false, false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			if v := TrimString(s[start:i]); v != "" {
+				vv = append(vv, v)
+			}
+			start = i + 1
+		}
+	}
+	if v := TrimString(s[start:]); v != "" {
+		vv = append(vv, v)
+	}
+	return vv
+}
+
 // Del deletes the values associated with key.
 func (h MIMEHeader) Del(key string) {
 	delete(h, CanonicalMIMEHeaderKey(key))
 }
+
+// Size returns the number of bytes h occupies when written in wire
+// form, with each value on its own "Key: value\r\n" line. Keys are
+// counted as stored in h; canonicalizing a key does not change its
+// length. Size does not include the blank line ending a header block.
+func (h MIMEHeader) Size() int {
+	n := 0
+	for k, vv := range h {
+		for _, v := range vv {
+			n += len(k) + len(": ") + len(v) + len("\r\n")
+		}
+	}
+	return n
+}
+
+// ParseHeaderLines parses a header given as its lines in wire form,
+// such as "Content-Type: text/plain", as ReadMIMEHeader would read it.
+// A trailing "\r\n" or "\n" on a line is ignored, and parsing stops
+// at the first empty line, which ends a header.
+//
+// A line beginning with a space or tab continues the value of the
+// previous line; the two are joined with a single space. Keys are
+// canonicalized, values are trimmed of surrounding spaces and tabs, and
+// lines with the same key add values in order. Lines with an empty key
+// are skipped. ParseHeaderLines returns a ProtocolError for a line that
+// lacks a colon or for a continuation line with no line to continue.
+func ParseHeaderLines(lines []string) (MIMEHeader, error) {
+	m := make(MIMEHeader)
+	var key, value string // the pending header field, if key != ""
+	flush := func() {
+		if key != "" {
+			m[key] = append(m[key], value)
+		}
+	}
+	for i, line := range lines {
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line == "" {
+			break
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if i == 0 {
+				return nil, ProtocolError("malformed MIME header initial line: " + line)
+			}
+			if key != "" {
+				value += " " + TrimString(line)
+			}
+			continue
+		}
+		flush()
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, ProtocolError(fmt.Sprintf("malformed MIME header: missing colon: %q", line))
+		}
+		key, value = CanonicalMIMEHeaderKey(k), TrimString(v)
+	}
+	flush()
+	return m, nil
+}
+
+// ReadOnly returns a read-only view of h.
+// The view shares h's storage rather than copying it, so later
+// changes made through h are visible through the view.
+func (h MIMEHeader) ReadOnly() ReadOnlyHeader {
+	return ReadOnlyHeader{h: h}
+}
+
+// A ReadOnlyHeader is a read-only view of a MIMEHeader, suitable for
+// handing headers to code that must not modify them.
+// It provides no methods that mutate the underlying header, and
+// methods returning values return copies.
+// The zero ReadOnlyHeader is an empty header.
+type ReadOnlyHeader struct {
+	h MIMEHeader
+}
+
+// Get gets the first value associated with the given key.
+// It is case insensitive; CanonicalMIMEHeaderKey is used
+// to canonicalize the provided key.
+// If there are no values associated with the key, Get returns "".
+func (h ReadOnlyHeader) Get(key string) string {
+	return h.h.Get(key)
+}
+
+// Values returns a copy of all values associated with the given key.
+// It is case insensitive; CanonicalMIMEHeaderKey is
+// used to canonicalize the provided key.
+func (h ReadOnlyHeader) Values(key string) []string {
+	v := h.h.Values(key)
+	if v == nil {
+		return nil
+	}
+	return append([]string(nil), v...)
+}
+
+// Len returns the number of keys in the header.
+func (h ReadOnlyHeader) Len() int {
+	return len(h.h)
+}
+
+// Range calls f sequentially for each key and value in the header,
+// in unspecified order. A key with several values is visited once
+// per value, in the order the values were added.
+// If f returns false, Range stops the iteration.
+func (h ReadOnlyHeader) Range(f func(key, value string) bool) {
+	for k, vv := range h.h {
+		for _, v := range vv {
+			if !f(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// A CasePreservingHeader is a MIME-style header that, like MIMEHeader,
+// looks up keys case-insensitively through CanonicalMIMEHeaderKey, but
+// also remembers the casing with which each key was added, so that a
+// proxy can relay header fields exactly as it received them.
+// The zero CasePreservingHeader is an empty header ready to use.
+type CasePreservingHeader struct {
+	h    MIMEHeader
+	orig map[string]string // canonical key -> key as added
+}
+
+// Add adds the key, value pair to the header.
+// It appends to any existing values associated with key.
+// If key is new, its casing is recorded as the original one;
+// otherwise the casing recorded earlier is kept.
+func (h *CasePreservingHeader) Add(key, value string) {
+	ck := h.record(key, false)
+	h.h[ck] = append(h.h[ck], value)
+}
+
+// Set sets the header entries associated with key to the single
+// element value, replacing any existing values and recording the
+// casing of key as the original one.
+func (h *CasePreservingHeader) Set(key, value string) {
+	ck := h.record(key, true)
+	h.h[ck] = []string{value}
+}
+
+// record records the casing of key unless it is already known and
+// replace is false, and returns the canonical key.
+func (h *CasePreservingHeader) record(key string, replace bool) string {
+	if h.h == nil {
+		h.h = make(MIMEHeader)
+		h.orig = make(map[string]string)
+	}
+	ck := CanonicalMIMEHeaderKey(key)
+	if _, ok := h.orig[ck]; replace || !ok {
+		h.orig[ck] = key
+	}
+	return ck
+}
+
+// Get gets the first value associated with the given key.
+// It is case insensitive; CanonicalMIMEHeaderKey is used
+// to canonicalize the provided key.
+// If there are no values associated with the key, Get returns "".
+func (h *CasePreservingHeader) Get(key string) string {
+	return h.h.Get(key)
+}
+
+// Values returns all values associated with the given key.
+// It is case insensitive; CanonicalMIMEHeaderKey is
+// used to canonicalize the provided key.
+// The returned slice is not a copy.
+func (h *CasePreservingHeader) Values(key string) []string {
+	return h.h.Values(key)
+}
+
+// Del deletes the values associated with key, and its original casing.
+func (h *CasePreservingHeader) Del(key string) {
+	ck := CanonicalMIMEHeaderKey(key)
+	delete(h.h, ck)
+	delete(h.orig, ck)
+}
+
+// Original returns the casing with which key was added to the header,
+// and reports whether the header has key at all.
+func (h *CasePreservingHeader) Original(key string) (string, bool) {
+	orig, ok := h.orig[CanonicalMIMEHeaderKey(key)]
+	return orig, ok
+}
+
+// MIMEHeader returns the header with canonicalized keys. It shares
+// h's storage rather than copying it; keys added to it directly have
+// no recorded original casing and are reported in canonical form.
+func (h *CasePreservingHeader) MIMEHeader() MIMEHeader {
+	if h.h == nil {
+		h.h = make(MIMEHeader)
+		h.orig = make(map[string]string)
+	}
+	return h.h
+}
+
+// EachOriginal calls f for each key in the header, in order of their
+// canonical forms, with the key as originally added, its canonical
+// form, and its values. The values slice is not a copy.
+func (h *CasePreservingHeader) EachOriginal(f func(original, canonical string, values []string)) {
+	keys := make([]string, 0, len(h.h))
+	for k := range h.h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		orig, ok := h.orig[k]
+		if !ok {
+			orig = k
+		}
+		f(orig, k, h.h[k])
+	}
+}
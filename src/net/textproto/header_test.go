@@ -4,7 +4,10 @@
 
 package textproto
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 type canonicalHeaderKeyTest struct {
 	in, out string
@@ -41,6 +44,28 @@ func TestCanonicalMIMEHeaderKey(t *testing.T) {
 	}
 }
 
+func TestCanonicalMIMEHeaderKeyStrict(t *testing.T) {
+	tests := []struct {
+		in, out string
+		ok      bool
+	}{
+		{"user-agent", "User-Agent", true},
+		{"USER-AGENT", "User-Agent", true},
+		{"foo-bar_baz", "Foo-Bar_baz", true},
+		{"", "", false},
+		{"üser-agenT", "üser-agenT", false},
+		{"a B", "a B", false},
+		{"a:b", "a:b", false},
+		{"a\x00b", "a\x00b", false},
+		{"a\xffb", "a\xffb", false},
+	}
+	for _, tt := range tests {
+		if s, ok := CanonicalMIMEHeaderKeyStrict(tt.in); s != tt.out || ok != tt.ok {
+			t.Errorf("CanonicalMIMEHeaderKeyStrict(%q) = %q, %v, want %q, %v", tt.in, s, ok, tt.out, tt.ok)
+		}
+	}
+}
+
 // Issue #34799 add a Header method to get multiple values []string, with canonicalized key
 func TestMIMEHeaderMultipleValues(t *testing.T) {
 	testHeader := MIMEHeader{
@@ -52,3 +77,261 @@ func TestMIMEHeaderMultipleValues(t *testing.T) {
 		t.Errorf("count: %d; want 2", n)
 	}
 }
+
+var validHeaderFieldNameTests = []struct {
+	key  string
+	want bool
+}{
+	{"Content-Type", true},
+	{"x-custom_header.1", true},
+	{"!#$%&'*+-.^_`|~", true},
+	{"", false},
+	{"Foo Bar", false},
+	{"Foo:Bar", false},
+	{"Foo\r\nX-Injected", false},
+	{"Foo\x00", false},
+	{"Foo\x7f", false},
+	{"Foo(bar)", false},
+	{"Föo", false},
+}
+
+func TestValidHeaderFieldName(t *testing.T) {
+	for _, tt := range validHeaderFieldNameTests {
+		if got := ValidHeaderFieldName(tt.key); got != tt.want {
+			t.Errorf("ValidHeaderFieldName(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestMIMEHeaderAddValid(t *testing.T) {
+	h := MIMEHeader{}
+	if err := h.AddValid("x-foo", "bar"); err != nil {
+		t.Fatalf("AddValid(%q): %v", "x-foo", err)
+	}
+	if err := h.AddValid("X-Bad\r\nSet-Cookie", "evil"); err == nil {
+		t.Fatalf("AddValid accepted an invalid field name")
+	}
+	want := MIMEHeader{"X-Foo": {"bar"}}
+	if len(h) != len(want) || h.Get("X-Foo") != "bar" {
+		t.Errorf("header = %v, want %v", h, want)
+	}
+}
+
+func TestReadOnlyHeader(t *testing.T) {
+	h := MIMEHeader{}
+	h.Add("X-Foo", "a")
+	h.Add("X-Foo", "b")
+	h.Add("X-Bar", "c")
+	ro := h.ReadOnly()
+	if got := ro.Get("x-foo"); got != "a" {
+		t.Errorf("Get(%q) = %q, want %q", "x-foo", got, "a")
+	}
+	if got := ro.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+	vv := ro.Values("X-Foo")
+	if len(vv) != 2 || vv[0] != "a" || vv[1] != "b" {
+		t.Fatalf("Values(%q) = %q, want [a b]", "X-Foo", vv)
+	}
+	vv[0] = "modified"
+	if got := h.Get("X-Foo"); got != "a" {
+		t.Errorf("modifying Values result changed header: Get = %q", got)
+	}
+	n := 0
+	ro.Range(func(key, value string) bool {
+		n++
+		return true
+	})
+	if n != 3 {
+		t.Errorf("Range visited %d values, want 3", n)
+	}
+	n = 0
+	ro.Range(func(key, value string) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Range after stop visited %d values, want 1", n)
+	}
+
+	// Changes to the underlying header are visible through the view.
+	h.Set("X-Baz", "d")
+	if got := ro.Get("X-Baz"); got != "d" {
+		t.Errorf("Get(%q) after Set = %q, want %q", "X-Baz", got, "d")
+	}
+
+	var zero ReadOnlyHeader
+	if zero.Get("X-Foo") != "" || zero.Values("X-Foo") != nil || zero.Len() != 0 {
+		t.Errorf("zero ReadOnlyHeader is not empty")
+	}
+}
+
+func TestMIMEHeaderJoined(t *testing.T) {
+	h := MIMEHeader{}
+	if got := h.GetJoined("Accept"); got != "" {
+		t.Errorf("GetJoined of missing key = %q, want empty", got)
+	}
+	h.Add("accept", "text/html")
+	h.Add("Accept", "text/plain;q=0.5")
+	if got, want := h.GetJoined("ACCEPT"), "text/html, text/plain;q=0.5"; got != want {
+		t.Errorf("GetJoined = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		joined string
+		want   []string
+	}{
+		{"a, b,c", []string{"a", "b", "c"}},
+		{` a ,, "b, c" ,`, []string{"a", `"b, c"`}},
+		{`"x\", y", z`, []string{`"x\", y"`, "z"}},
+		{`W/"1,2", "3"`, []string{`W/"1,2"`, `"3"`}},
+		{" , ", nil},
+	}
+	for _, tt := range tests {
+		h.SetJoined("x-list", tt.joined)
+		if got := h["X-List"]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SetJoined(%q) = %q, want %q", tt.joined, got, tt.want)
+		}
+	}
+	if _, ok := h["X-List"]; ok {
+		t.Errorf("SetJoined with empty list did not delete key")
+	}
+}
+
+func TestMIMEHeaderSize(t *testing.T) {
+	h := MIMEHeader{
+		"Accept":       {"text/html", "text/plain"},
+		"Content-Type": {"text/plain"},
+		"X-Empty":      {""},
+		"X-None":       {},
+	}
+	wire := "Accept: text/html\r\n" +
+		"Accept: text/plain\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"X-Empty: \r\n"
+	if got, want := h.Size(), len(wire); got != want {
+		t.Errorf("Size() = %d, want %d", got, want)
+	}
+	if got := MIMEHeader(nil).Size(); got != 0 {
+		t.Errorf("nil Size() = %d, want 0", got)
+	}
+	if n := testing.AllocsPerRun(100, func() { h.Size() }); n != 0 {
+		t.Errorf("Size allocates %v times, want 0", n)
+	}
+}
+
+func TestMIMEHeaderGetList(t *testing.T) {
+	h := MIMEHeader{
+		"Accept": {`text/html, text/plain;q=0.5`, `application/json`},
+		"Etag":   {`W/"1,2", "3"`},
+		"Set-Cookie": {
+			"a=1; Expires=Wed, 21 Oct 2015 07:28:00 GMT",
+			"b=2",
+		},
+	}
+	tests := []struct {
+		key      string
+		foldable bool
+		want     []string
+	}{
+		{"accept", true, []string{"text/html", "text/plain;q=0.5", "application/json"}},
+		{"accept", false, []string{`text/html, text/plain;q=0.5`, `application/json`}},
+		{"ETag", true, []string{`W/"1,2"`, `"3"`}},
+		{"set-cookie", false, []string{"a=1; Expires=Wed, 21 Oct 2015 07:28:00 GMT", "b=2"}},
+		{"X-Missing", true, nil},
+		{"X-Missing", false, nil},
+	}
+	for _, tt := range tests {
+		if got := h.GetList(tt.key, tt.foldable); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetList(%q, %v) = %q, want %q", tt.key, tt.foldable, got, tt.want)
+		}
+	}
+}
+
+func TestParseHeaderLines(t *testing.T) {
+	lines := []string{
+		"my-key: Value 1  ",
+		"Long-key: Even \r\n",
+		"   Longer Value\n",
+		"my-Key: Value 2",
+		": skipped",
+		"Empty:",
+		"",
+		"After: blank line",
+	}
+	h, err := ParseHeaderLines(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MIMEHeader{
+		"My-Key":   {"Value 1", "Value 2"},
+		"Long-Key": {"Even Longer Value"},
+		"Empty":    {""},
+	}
+	if !reflect.DeepEqual(h, want) {
+		t.Errorf("ParseHeaderLines = %v, want %v", h, want)
+	}
+
+	for _, bad := range [][]string{
+		{" Folded: first"},
+		{"Good: line", "no colon"},
+	} {
+		if h, err := ParseHeaderLines(bad); err == nil {
+			t.Errorf("ParseHeaderLines(%q) = %v, want error", bad, h)
+		} else if _, ok := err.(ProtocolError); !ok {
+			t.Errorf("ParseHeaderLines(%q) error = %T, want ProtocolError", bad, err)
+		}
+	}
+}
+
+func TestCasePreservingHeader(t *testing.T) {
+	var h CasePreservingHeader
+	h.Add("x-request-id", "1")
+	h.Add("X-REQUEST-ID", "2")
+	h.Add("content-TYPE", "text/plain")
+	h.Set("WWW-Authenticate", "Basic")
+	h.Set("etag", "a")
+	h.Set("ETag", "b")
+	h.Add("Gone", "x")
+	h.Del("gone")
+
+	if got := h.Get("X-Request-Id"); got != "1" {
+		t.Errorf("Get(X-Request-Id) = %q, want 1", got)
+	}
+	if got, ok := h.Original("x-request-ID"); got != "x-request-id" || !ok {
+		t.Errorf("Original(x-request-ID) = %q, %v, want x-request-id, true", got, ok)
+	}
+	if got, ok := h.Original("gone"); got != "" || ok {
+		t.Errorf("Original(gone) after Del = %q, %v, want \"\", false", got, ok)
+	}
+
+	h.MIMEHeader()["Direct"] = []string{"d"}
+
+	type entry struct {
+		original, canonical string
+		values              []string
+	}
+	var got []entry
+	h.EachOriginal(func(original, canonical string, values []string) {
+		got = append(got, entry{original, canonical, values})
+	})
+	want := []entry{
+		{"content-TYPE", "Content-Type", []string{"text/plain"}},
+		{"Direct", "Direct", []string{"d"}},
+		{"ETag", "Etag", []string{"b"}},
+		{"WWW-Authenticate", "Www-Authenticate", []string{"Basic"}},
+		{"x-request-id", "X-Request-Id", []string{"1", "2"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EachOriginal visited %v, want %v", got, want)
+	}
+
+	var empty CasePreservingHeader
+	empty.EachOriginal(func(original, canonical string, values []string) {
+		t.Errorf("EachOriginal on empty header visited %q", original)
+	})
+	if got := empty.Get("A"); got != "" {
+		t.Errorf("empty.Get(A) = %q, want \"\"", got)
+	}
+	empty.Del("A")
+}
@@ -612,6 +612,17 @@ func CanonicalMIMEHeaderKey(s string) string {
 	return s
 }
 
+// CanonicalMIMEHeaderKeyStrict is like CanonicalMIMEHeaderKey but
+// rejects keys that are not valid header field names. If s is empty or
+// contains a space, a non-ASCII byte or any other byte that is not a
+// token character, it returns s unchanged and false.
+func CanonicalMIMEHeaderKeyStrict(s string) (string, bool) {
+	if !ValidHeaderFieldName(s) {
+		return s, false
+	}
+	return CanonicalMIMEHeaderKey(s), true
+}
+
 const toLower = 'a' - 'A'
 
 // validHeaderFieldByte reports whether b is a valid byte in a header
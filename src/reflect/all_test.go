@@ -1087,6 +1087,384 @@ func TestDeepEqualRecursiveStruct(t *testing.T) {
 	}
 }
 
+func TestDeepEqualFuncVisitedLimit(t *testing.T) {
+	for _, limit := range []int{0, 1, 2, 100} {
+		opts := DeepEqualOptions{VisitedLimit: limit}
+		for _, test := range deepEqualTests {
+			if test.b == (self{}) {
+				test.b = test.a
+			}
+			if r := DeepEqualFunc(test.a, test.b, opts); r != test.eq {
+				t.Errorf("DeepEqualFunc(%#v, %#v, %+v) = %v, want %v", test.a, test.b, opts, r, test.eq)
+			}
+		}
+
+		// A cycle longer than the limit must still terminate.
+		const n = 10
+		a, b := make([]*Recursive, n), make([]*Recursive, n)
+		for i := range a {
+			a[i], b[i] = &Recursive{x: i}, &Recursive{x: i}
+		}
+		for i := range a {
+			a[i].r, b[i].r = a[(i+1)%n], b[(i+1)%n]
+		}
+		if !DeepEqualFunc(a, b, opts) {
+			t.Errorf("DeepEqualFunc(long cycle, %+v) = false, want true", opts)
+		}
+		b[n-1].x = -1
+		if DeepEqualFunc(a, b, opts) {
+			t.Errorf("DeepEqualFunc(different long cycle, %+v) = true, want false", opts)
+		}
+	}
+}
+
+func TestDeepEqualFuncComparableTypes(t *testing.T) {
+	type withPtr struct{ P *int }
+	type withIface struct{ X any }
+	one, otherOne := 1, 1
+	opts := DeepEqualOptions{ComparableTypes: map[Type]bool{
+		TypeOf(withPtr{}):   true,
+		TypeOf(withIface{}): true,
+		TypeOf([]int{}):     true,
+	}}
+	tests := []struct {
+		a, b      any
+		eq, optEq bool
+	}{
+		{withPtr{&one}, withPtr{&one}, true, true},
+		{withPtr{&one}, withPtr{&otherOne}, true, false},
+		{[]withPtr{{&one}}, []withPtr{{&otherOne}}, true, false},
+		// Types whose == could panic are compared recursively.
+		{withIface{[]int{1}}, withIface{[]int{1}}, true, true},
+		{[]int{1}, []int{1}, true, true},
+	}
+	for _, tt := range tests {
+		if got := DeepEqualFunc(tt.a, tt.b, DeepEqualOptions{}); got != tt.eq {
+			t.Errorf("DeepEqualFunc(%v, %v, {}) = %v, want %v", tt.a, tt.b, got, tt.eq)
+		}
+		if got := DeepEqualFunc(tt.a, tt.b, opts); got != tt.optEq {
+			t.Errorf("DeepEqualFunc(%v, %v, ComparableTypes) = %v, want %v", tt.a, tt.b, got, tt.optEq)
+		}
+	}
+}
+
+func TestDeepEqualFuncFuncs(t *testing.T) {
+	type handlers struct {
+		Name string
+		OnA  func()
+		OnB  func(int) int
+	}
+	f1 := func() {}
+	f2 := func() {}
+	double := func(x int) int { return 2 * x }
+	byPtr := DeepEqualOptions{CompareFuncsByPointer: true}
+	tests := []struct {
+		a, b      any
+		eq, ptrEq bool
+	}{
+		{handlers{"h", f1, double}, handlers{"h", f1, double}, false, true},
+		{handlers{"h", f1, double}, handlers{"h", f2, double}, false, false},
+		{handlers{"h", nil, double}, handlers{"h", nil, double}, false, true},
+		{handlers{"h", nil, nil}, handlers{"h", nil, nil}, true, true},
+		{handlers{"h", f1, nil}, handlers{"h", nil, nil}, false, false},
+		{handlers{"h", f1, nil}, handlers{"g", f1, nil}, false, false},
+	}
+	for _, tt := range tests {
+		if got := DeepEqualFunc(tt.a, tt.b, DeepEqualOptions{}); got != tt.eq {
+			t.Errorf("DeepEqualFunc(%v, %v, {}) = %v, want %v", tt.a, tt.b, got, tt.eq)
+		}
+		if got := DeepEqualFunc(tt.a, tt.b, byPtr); got != tt.ptrEq {
+			t.Errorf("DeepEqualFunc(%v, %v, CompareFuncsByPointer) = %v, want %v", tt.a, tt.b, got, tt.ptrEq)
+		}
+	}
+}
+
+type sameAddrBox struct{}
+
+func TestDeepEqualSameAddress(t *testing.T) {
+	shared := struct {
+		F func()
+		N float64
+	}{func() {}, math.NaN()}
+	// An iterator that returns the same variable for both boxes makes
+	// deepValueEqual compare an addressable value with itself.
+	iters := DeepEqualOptions{Iterators: map[Type]func(Value) []MapEntry{
+		TypeOf(sameAddrBox{}): func(Value) []MapEntry {
+			return []MapEntry{{ValueOf("k"), ValueOf(&shared).Elem()}}
+		},
+	}}
+	if !DeepEqualFunc(sameAddrBox{}, sameAddrBox{}, iters) {
+		t.Errorf("DeepEqualFunc of a variable with itself = false, want true")
+	}
+
+	// Copies of a value are not the same variable, even if they share
+	// memory, so a func or NaN is still not equal to itself.
+	var x any = shared
+	if DeepEqual(x, x) {
+		t.Errorf("DeepEqual(x, x) = true for x holding a func and NaN, want false")
+	}
+	if DeepEqual(shared, shared) {
+		t.Errorf("DeepEqual(shared, shared) = true, want false")
+	}
+	if !DeepEqual(&shared, &shared) {
+		t.Errorf("DeepEqual(&shared, &shared) = false, want true")
+	}
+}
+
+type equalByLen []int
+
+func (x equalByLen) Equal(y equalByLen) bool { return len(x) == len(y) }
+
+type equalPtr struct{ n int }
+
+func (x *equalPtr) Equal(y *equalPtr) bool { return x.n%2 == y.n%2 }
+
+type equalWrongSig struct{ n int }
+
+func (x equalWrongSig) Equal(y any) bool { return true }
+
+func TestDeepEqualFuncEqualMethod(t *testing.T) {
+	type event struct {
+		When time.Time
+		data equalByLen
+	}
+	now := time.Now()
+	utc := now.UTC()
+	useEqual := DeepEqualOptions{UseEqualMethod: true}
+	tests := []struct {
+		a, b        any
+		eq, equalEq bool
+	}{
+		{now, utc, false, true},
+		{event{now, equalByLen{1}}, event{utc, equalByLen{2}}, false, true},
+		{event{now, equalByLen{1}}, event{now, equalByLen{1, 2}}, false, false},
+		{event{now, nil}, event{now.Add(1), nil}, false, false},
+		{&equalPtr{1}, &equalPtr{3}, false, true},
+		{&equalPtr{1}, &equalPtr{2}, false, false},
+		{[]*equalPtr{nil}, []*equalPtr{nil}, true, true},
+		{[]*equalPtr{nil}, []*equalPtr{{1}}, false, false},
+		{equalWrongSig{1}, equalWrongSig{2}, false, false},
+		{map[string]any{"t": now}, map[string]any{"t": utc}, false, true},
+	}
+	for _, tt := range tests {
+		if got := DeepEqualFunc(tt.a, tt.b, DeepEqualOptions{}); got != tt.eq {
+			t.Errorf("DeepEqualFunc(%v, %v, {}) = %v, want %v", tt.a, tt.b, got, tt.eq)
+		}
+		if got := DeepEqualFunc(tt.a, tt.b, useEqual); got != tt.equalEq {
+			t.Errorf("DeepEqualFunc(%v, %v, UseEqualMethod) = %v, want %v", tt.a, tt.b, got, tt.equalEq)
+		}
+	}
+}
+
+func TestDeepEqualFuncFloats(t *testing.T) {
+	nan, negZero := math.NaN(), math.Copysign(0, -1)
+	tests := []struct {
+		a, b                any
+		eq, nanEq, signedEq bool
+	}{
+		{1.5, 1.5, true, true, true},
+		{nan, nan, false, true, false},
+		{float32(nan), float32(nan), false, true, false},
+		{nan, 1.0, false, false, false},
+		{negZero, 0.0, true, true, false},
+		{float32(negZero), float32(0), true, true, false},
+		{negZero, negZero, true, true, true},
+		{complex(nan, 1), complex(nan, 1), false, true, false},
+		{complex(1, negZero), complex(1, 0), true, true, false},
+		{[]float64{1, nan}, []float64{1, nan}, false, true, false},
+		{map[string]float64{"z": negZero}, map[string]float64{"z": 0}, true, true, false},
+	}
+	for _, tt := range tests {
+		if got := DeepEqualFunc(tt.a, tt.b, DeepEqualOptions{}); got != tt.eq {
+			t.Errorf("DeepEqualFunc(%v, %v, {}) = %v, want %v", tt.a, tt.b, got, tt.eq)
+		}
+		if got := DeepEqualFunc(tt.a, tt.b, DeepEqualOptions{NaNEqual: true}); got != tt.nanEq {
+			t.Errorf("DeepEqualFunc(%v, %v, NaNEqual) = %v, want %v", tt.a, tt.b, got, tt.nanEq)
+		}
+		if got := DeepEqualFunc(tt.a, tt.b, DeepEqualOptions{DistinguishSignedZero: true}); got != tt.signedEq {
+			t.Errorf("DeepEqualFunc(%v, %v, DistinguishSignedZero) = %v, want %v", tt.a, tt.b, got, tt.signedEq)
+		}
+	}
+}
+
+func TestDeepEqualFuncIterators(t *testing.T) {
+	syncMap := func(kvs ...any) *sync.Map {
+		m := new(sync.Map)
+		for i := 0; i < len(kvs); i += 2 {
+			m.Store(kvs[i], kvs[i+1])
+		}
+		return m
+	}
+	opts := DeepEqualOptions{Iterators: map[Type]func(Value) []MapEntry{
+		TypeOf(new(sync.Map)): func(v Value) []MapEntry {
+			var entries []MapEntry
+			v.Interface().(*sync.Map).Range(func(k, v any) bool {
+				entries = append(entries, MapEntry{ValueOf(k), ValueOf(v)})
+				return true
+			})
+			return entries
+		},
+	}}
+	tests := []struct {
+		a, b *sync.Map
+		eq   bool
+	}{
+		{syncMap(), syncMap(), true},
+		{syncMap("a", 1, "b", []int{2}), syncMap("b", []int{2}, "a", 1), true},
+		{syncMap("a", 1), syncMap("a", 2), false},
+		{syncMap("a", 1), syncMap("b", 1), false},
+		{syncMap("a", 1), syncMap("a", 1, "b", 2), false},
+	}
+	for _, tt := range tests {
+		if got := DeepEqualFunc(tt.a, tt.b, opts); got != tt.eq {
+			t.Errorf("DeepEqualFunc(%v, %v, Iterators) = %v, want %v", tt.a, tt.b, got, tt.eq)
+		}
+	}
+	type holder struct{ M *sync.Map }
+	if !DeepEqualFunc(holder{syncMap("a", 1)}, holder{syncMap("a", 1)}, opts) {
+		t.Errorf("DeepEqualFunc of structs holding equal sync.Maps = false, want true")
+	}
+	// The iterator can use values of unexported fields.
+	type unexportedHolder struct{ m *sync.Map }
+	if !DeepEqualFunc(unexportedHolder{syncMap("a", 1)}, unexportedHolder{syncMap("a", 1)}, opts) {
+		t.Errorf("DeepEqualFunc of structs with equal unexported sync.Maps = false, want true")
+	}
+	if DeepEqualFunc(unexportedHolder{syncMap("a", 1)}, unexportedHolder{syncMap("a", 2)}, opts) {
+		t.Errorf("DeepEqualFunc of structs with unequal unexported sync.Maps = true, want false")
+	}
+	// Values of unexported fields reached through a pointer must
+	// still not be settable.
+	type counter struct{ n int }
+	type counterHolder struct{ in counter }
+	canSet := false
+	counterOpts := DeepEqualOptions{Iterators: map[Type]func(Value) []MapEntry{
+		TypeOf(counter{}): func(v Value) []MapEntry {
+			canSet = canSet || v.CanSet() || v.Field(0).CanSet()
+			return []MapEntry{{ValueOf("n"), ValueOf(v.Field(0).Int())}}
+		},
+	}}
+	if !DeepEqualFunc(&counterHolder{counter{1}}, &counterHolder{counter{1}}, counterOpts) {
+		t.Errorf("DeepEqualFunc of pointers to structs with equal unexported counters = false, want true")
+	}
+	if canSet {
+		t.Errorf("iterator got a settable value of an unexported field")
+	}
+}
+
+func TestDeepEqualStats(t *testing.T) {
+	for _, test := range deepEqualTests {
+		if test.b == (self{}) {
+			test.b = test.a
+		}
+		eq, n, m := DeepEqualStats(test.a, test.b)
+		if eq != test.eq {
+			t.Errorf("DeepEqualStats(%#v, %#v) = %v, want %v", test.a, test.b, eq, test.eq)
+		}
+		if m > n || (m == 0) != eq {
+			t.Errorf("DeepEqualStats(%#v, %#v) = %v, %d comparisons, %d mismatches", test.a, test.b, eq, n, m)
+		}
+	}
+
+	type pair struct {
+		A []int
+		B map[string]string
+		C []byte
+	}
+	x := pair{[]int{1, 2, 3, 4}, map[string]string{"k": "v", "l": "w"}, []byte("abc")}
+	y := pair{[]int{1, 0, 3, 0}, map[string]string{"k": "v", "l": "x"}, []byte("abd")}
+	eq, n, m := DeepEqualStats(x, y)
+	if eq || n != 9 || m != 4 {
+		t.Errorf("DeepEqualStats(%v, %v) = %v, %d, %d, want false, 9, 4", x, y, eq, n, m)
+	}
+
+	// Cycles still terminate.
+	a, b := &Recursive{x: 1}, &Recursive{x: 2}
+	a.r, b.r = a, b
+	eq, n, m = DeepEqualStats(a, b)
+	if eq || m != 1 {
+		t.Errorf("DeepEqualStats(cycle) = %v, %d, %d, want false, _, 1", eq, n, m)
+	}
+}
+
+func TestDeepEqualAll(t *testing.T) {
+	for _, test := range deepEqualTests {
+		if test.b == (self{}) {
+			test.b = test.a
+		}
+		if diffs := DeepEqualAll(test.a, test.b); (len(diffs) == 0) != test.eq {
+			t.Errorf("DeepEqualAll(%#v, %#v) = %v, want equal %v", test.a, test.b, diffs, test.eq)
+		}
+	}
+
+	type item struct {
+		Name string
+		Tags map[string]int
+		next *item
+	}
+	x := []any{
+		item{Name: "a", Tags: map[string]int{"k": 1, "x": 0}},
+		&item{Name: "b", next: &item{Name: "c"}},
+		[]int{1, 2},
+	}
+	y := []any{
+		item{Name: "A", Tags: map[string]int{"k": 2, "y": 0}},
+		&item{Name: "b", next: &item{Name: "C"}},
+		[]int{1, 3, 4},
+		"extra",
+	}
+	type diff struct {
+		path string
+		x, y any
+	}
+	want := map[string]diff{
+		"[0].Name":      {"[0].Name", "a", "A"},
+		`[0].Tags["k"]`: {`[0].Tags["k"]`, 1, 2},
+		`[0].Tags["x"]`: {`[0].Tags["x"]`, 0, nil},
+		`[0].Tags["y"]`: {`[0].Tags["y"]`, nil, 0},
+		"[1].next.Name": {"[1].next.Name", "c", "C"},
+		"[2][1]":        {"[2][1]", 2, 3},
+		"[2][2]":        {"[2][2]", nil, 4},
+		"[3]":           {"[3]", nil, "extra"},
+	}
+	iface := func(v Value) any {
+		switch v.Kind() {
+		case Invalid:
+			return nil
+		case String:
+			return v.String()
+		case Int:
+			return int(v.Int())
+		}
+		return v.Interface()
+	}
+	diffs := DeepEqualAll(x, y)
+	for _, d := range diffs {
+		w, ok := want[d.Path]
+		if !ok {
+			t.Errorf("unexpected difference at %s: %v, %v", d.Path, d.X, d.Y)
+			continue
+		}
+		delete(want, d.Path)
+		if iface(d.X) != w.x || iface(d.Y) != w.y {
+			t.Errorf("difference at %s = %v, %v, want %v, %v", d.Path, d.X, d.Y, w.x, w.y)
+		}
+	}
+	for _, w := range want {
+		t.Errorf("missing difference at %s", w.path)
+	}
+
+	// Cycles still terminate.
+	a, b := &Recursive{x: 1}, &Recursive{x: 2}
+	a.r, b.r = a, b
+	if diffs := DeepEqualAll(a, b); len(diffs) != 1 || diffs[0].Path != ".x" {
+		t.Errorf("DeepEqualAll(cycle) = %v, want one difference at .x", diffs)
+	}
+
+	if diffs := DeepEqualAll(nil, 1); len(diffs) != 1 || diffs[0].Path != "" || diffs[0].X.IsValid() {
+		t.Errorf("DeepEqualAll(nil, 1) = %v, want one difference with no X", diffs)
+	}
+}
+
 type _Complex struct {
 	a int
 	b [3]*_Complex
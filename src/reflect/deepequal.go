@@ -8,6 +8,8 @@ package reflect
 
 import (
 	"internal/bytealg"
+	"math"
+	"strconv"
 	"unsafe"
 )
 
@@ -21,15 +23,302 @@ type visit struct {
 	typ Type
 }
 
+// DeepEqualOptions configures the comparison performed by DeepEqualFunc.
+// The zero DeepEqualOptions gives the same behavior as DeepEqual.
+//
+// Like DeepEqual, DeepEqualFunc compares unexported struct fields as
+// well as exported ones, and the options apply to values reached through
+// unexported fields just as to any others. Values passed to functions
+// and methods named by the options, such as Iterators and Equal methods,
+// may therefore come from unexported fields. They are passed as if they
+// had been obtained through exported fields, so that, for example, an
+// iterator can call Interface on them without panicking.
+type DeepEqualOptions struct {
+	// VisitedLimit, if positive, bounds the number of completed
+	// comparisons of references that are remembered while comparing.
+	// When the limit is exceeded, the oldest completed comparisons are
+	// forgotten, so shared substructures reached again may be compared
+	// again. Comparisons that are still in progress are always
+	// remembered, so cycles are still detected and the comparison
+	// terminates. Zero means no limit, as in DeepEqual.
+	VisitedLimit int
+
+	// ComparableTypes lists types whose values are compared with Go's
+	// == operator instead of recursively, which can be faster for
+	// small structs and arrays. For example, pointers within such
+	// values are equal only if they are identical. Types whose ==
+	// could panic, because they are not comparable or contain
+	// interfaces, are compared recursively as usual.
+	ComparableTypes map[Type]bool
+
+	// Iterators maps types whose contents are not visible through
+	// their fields, such as *sync.Map, to functions that list the
+	// key-value pairs they contain. Values of such types are compared
+	// like maps: they are deeply equal if their iterators return the
+	// same number of entries and the keys (matched using Go equality)
+	// map to deeply equal values. The keys must therefore be
+	// comparable. Values of other types are compared as usual.
+	Iterators map[Type]func(v Value) []MapEntry
+
+	// NaNEqual makes floating-point NaNs equal to each other,
+	// including in the real and imaginary parts of complex numbers.
+	// By default, as with ==, a NaN is not equal to anything.
+	NaNEqual bool
+
+	// DistinguishSignedZero makes floating-point -0 and +0 unequal,
+	// including in the real and imaginary parts of complex numbers.
+	// By default, as with ==, they are equal.
+	DistinguishSignedZero bool
+
+	// CompareFuncsByPointer makes non-nil func values equal if they
+	// have the same code pointer, as reported by Value.Pointer.
+	// As that method notes, a code pointer does not necessarily
+	// identify a function uniquely: closures of the same function
+	// literal share one regardless of what they capture, for example.
+	// By default, func values are equal only if both are nil.
+	CompareFuncsByPointer bool
+
+	// UseEqualMethod makes values whose type T has a method
+	// Equal(T) bool, such as time.Time and net.IP, equal if that
+	// method reports them equal, instead of comparing them
+	// recursively. Nil pointers are still compared as usual, without
+	// calling the method.
+	UseEqualMethod bool
+}
+
+// A MapEntry is a key-value pair listed by an iterator in
+// DeepEqualOptions.Iterators.
+type MapEntry struct {
+	Key, Value Value
+}
+
+// deepEqualState is the state of a single deep equality check
+// beyond the visited map.
+type deepEqualState struct {
+	opts *DeepEqualOptions
+
+	// completed is a ring of the completed comparisons in the
+	// visited map, oldest first starting at index next. It is only
+	// used when opts.VisitedLimit is positive.
+	completed []visit
+	next      int
+
+	// If countAll is set, the check does not stop at the first
+	// difference, and comparisons and mismatches count the
+	// comparisons decided without examining further values.
+	countAll    bool
+	comparisons int
+	mismatches  int
+
+	// If recordDiffs is set, countAll is set too, and each mismatch is
+	// recorded in diffs, and the lengths of slices and maps, and the
+	// keys of maps, are compared element by element. The values being
+	// compared are at path from the root. x and y are the values most
+	// recently passed to deepValueEqual: no comparison is decided after
+	// comparing elements, so they are the values being compared
+	// whenever compared is called.
+	recordDiffs bool
+	diffs       []Difference
+	path        []byte
+	x, y        Value
+
+	// shallow holds the types from opts.ComparableTypes whose values
+	// can be compared with == without panicking.
+	shallow map[Type]bool
+
+	// equalMethods caches the results of equalMethod.
+	equalMethods map[Type]Value
+}
+
+// newDeepEqualState returns the state for a check with options opts.
+func newDeepEqualState(opts *DeepEqualOptions) *deepEqualState {
+	s := &deepEqualState{opts: opts}
+	for t, ok := range opts.ComparableTypes {
+		if ok && t != nil && safeComparable(t) {
+			if s.shallow == nil {
+				s.shallow = make(map[Type]bool)
+			}
+			s.shallow[t] = true
+		}
+	}
+	return s
+}
+
+// safeComparable reports whether comparing values of type t with ==
+// cannot panic.
+func safeComparable(t Type) bool {
+	switch t.Kind() {
+	case Interface, Func, Map, Slice:
+		return false
+	case Array:
+		return safeComparable(t.Elem())
+	case Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !safeComparable(t.Field(i).Type) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// equalMethod returns the method expression for the method
+// Equal(t) bool of type t, or the zero Value if t has no such method.
+func (s *deepEqualState) equalMethod(t Type) Value {
+	if m, ok := s.equalMethods[t]; ok {
+		return m
+	}
+	var fn Value
+	if t.Kind() != Interface {
+		if m, ok := t.MethodByName("Equal"); ok {
+			mt := m.Type
+			if mt.NumIn() == 2 && mt.In(1) == t && mt.NumOut() == 1 && mt.Out(0).Kind() == Bool {
+				fn = m.Func
+			}
+		}
+	}
+	if s.equalMethods == nil {
+		s.equalMethods = make(map[Type]Value)
+	}
+	s.equalMethods[t] = fn
+	return fn
+}
+
+// exported returns v as if it had been obtained through exported
+// fields, so that values of unexported fields can be passed to the
+// functions named by DeepEqualOptions. Like valueInterface(v, false),
+// which the comparison uses itself, this lets the options see every
+// value that DeepEqual compares. The result is not addressable, so
+// the options cannot use it to modify the values being compared.
+func exported(v Value) Value {
+	v.flag &^= flagRO | flagAddr
+	return v
+}
+
+// compared records the result of a comparison that was decided
+// without examining any further values, and returns eq.
+func (s *deepEqualState) compared(eq bool) bool {
+	if s.countAll {
+		s.comparisons++
+		if !eq {
+			s.mismatches++
+			if s.recordDiffs {
+				s.diffs = append(s.diffs, Difference{Path: string(s.path), X: s.x, Y: s.y})
+			}
+		}
+	}
+	return eq
+}
+
+// missing records that only one of x and y, which are compared at the
+// current path, exists, and returns false.
+func (s *deepEqualState) missing(x, y Value) bool {
+	s.x, s.y = x, y
+	return s.compared(false)
+}
+
+// The enter methods append an element to the path if differences are
+// being recorded, and return the length of the path to restore with
+// leave once the element has been compared.
+
+func (s *deepEqualState) enterIndex(i int) int {
+	n := len(s.path)
+	if s.recordDiffs {
+		s.appendIndex(i)
+	}
+	return n
+}
+
+func (s *deepEqualState) enterField(t Type, i int) int {
+	n := len(s.path)
+	if s.recordDiffs {
+		s.appendField(t, i)
+	}
+	return n
+}
+
+func (s *deepEqualState) enterKey(k Value) int {
+	n := len(s.path)
+	if s.recordDiffs {
+		s.appendKey(k)
+	}
+	return n
+}
+
+func (s *deepEqualState) appendIndex(i int) {
+	s.path = append(strconv.AppendInt(append(s.path, '['), int64(i), 10), ']')
+}
+
+func (s *deepEqualState) appendField(t Type, i int) {
+	s.path = append(append(s.path, '.'), t.Field(i).Name...)
+}
+
+// appendKey appends the map key k to the path. Keys other than strings,
+// numbers and bools are shown by their type.
+func (s *deepEqualState) appendKey(k Value) {
+	b := append(s.path, '[')
+	switch k.Kind() {
+	case String:
+		b = strconv.AppendQuote(b, k.String())
+	case Int, Int8, Int16, Int32, Int64:
+		b = strconv.AppendInt(b, k.Int(), 10)
+	case Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+		b = strconv.AppendUint(b, k.Uint(), 10)
+	case Bool:
+		b = strconv.AppendBool(b, k.Bool())
+	case Float32, Float64:
+		b = strconv.AppendFloat(b, k.Float(), 'g', -1, 64)
+	default:
+		b = append(append(b, k.Type().String()...), " key"...)
+	}
+	s.path = append(b, ']')
+}
+
+func (s *deepEqualState) leave(n int) {
+	s.path = s.path[:n]
+}
+
+// finish records that the comparison v has completed, forgetting
+// the oldest completed comparison if the visited limit is exceeded.
+func (s *deepEqualState) finish(visited map[visit]bool, v visit) {
+	if len(s.completed) < s.opts.VisitedLimit {
+		s.completed = append(s.completed, v)
+		return
+	}
+	delete(visited, s.completed[s.next])
+	s.completed[s.next] = v
+	s.next = (s.next + 1) % len(s.completed)
+}
+
 // Tests for deep equality using reflected types. The map argument tracks
 // comparisons that have already been seen, which allows short circuiting on
 // recursive types.
-func deepValueEqual(v1, v2 Value, visited map[visit]bool) bool {
+func deepValueEqual(v1, v2 Value, visited map[visit]bool, s *deepEqualState) (eq bool) {
+	if s.recordDiffs {
+		s.x, s.y = v1, v2
+	}
 	if !v1.IsValid() || !v2.IsValid() {
-		return v1.IsValid() == v2.IsValid()
+		return s.compared(v1.IsValid() == v2.IsValid())
 	}
 	if v1.Type() != v2.Type() {
-		return false
+		return s.compared(false)
+	}
+	// Values stored at the same address are the same value, just as
+	// identical pointers are deeply equal without looking at what they
+	// point to. Only addressable values are known to be stored where
+	// v.ptr points: other values, such as two copies of the same
+	// interface, may share memory without being the same variable, and
+	// for them the usual rules for funcs and NaNs still apply.
+	if v1.flag&flagAddr != 0 && v2.flag&flagAddr != 0 && v1.ptr == v2.ptr {
+		return s.compared(true)
+	}
+	if s.shallow != nil && s.shallow[v1.Type()] {
+		return s.compared(valueInterface(v1, false) == valueInterface(v2, false))
+	}
+	if s.opts.UseEqualMethod {
+		if eq := s.equalMethod(v1.Type()); eq.IsValid() && (v1.Kind() != Pointer || !v1.IsNil() && !v2.IsNil()) {
+			return s.compared(eq.Call([]Value{exported(v1), exported(v2)})[0].Bool())
+		}
 	}
 
 	// We want to avoid putting more in the visited map than we need to.
@@ -80,98 +369,207 @@ func deepValueEqual(v1, v2 Value, visited map[visit]bool) bool {
 		typ := v1.Type()
 		v := visit{addr1, addr2, typ}
 		if visited[v] {
-			return true
+			return s.compared(true)
 		}
 
 		// Remember for later.
 		visited[v] = true
+		if s.opts.VisitedLimit > 0 {
+			// A comparison that returns false ends the whole check
+			// unless countAll is set, so only successful comparisons
+			// need to be recorded.
+			defer func() {
+				if eq {
+					s.finish(visited, v)
+				}
+			}()
+		}
+	}
+
+	if len(s.opts.Iterators) > 0 {
+		if iter := s.opts.Iterators[v1.Type()]; iter != nil {
+			return s.iterEqual(iter(exported(v1)), iter(exported(v2)), visited)
+		}
 	}
 
 	switch v1.Kind() {
 	case Array:
+		eq = true
 		for i := 0; i < v1.Len(); i++ {
-			if !deepValueEqual(v1.Index(i), v2.Index(i), visited) {
-				return false
+			n := s.enterIndex(i)
+			ok := deepValueEqual(v1.Index(i), v2.Index(i), visited, s)
+			s.leave(n)
+			if !ok {
+				if !s.countAll {
+					return false
+				}
+				eq = false
 			}
 		}
-		return true
+		return eq
 	case Slice:
 		if v1.IsNil() != v2.IsNil() {
-			return false
+			return s.compared(false)
 		}
-		if v1.Len() != v2.Len() {
-			return false
+		if v1.Len() != v2.Len() && !s.recordDiffs {
+			return s.compared(false)
 		}
-		if v1.UnsafePointer() == v2.UnsafePointer() {
-			return true
+		if v1.UnsafePointer() == v2.UnsafePointer() && v1.Len() == v2.Len() {
+			return s.compared(true)
 		}
 		// Special case for []byte, which is common.
-		if v1.Type().Elem().Kind() == Uint8 {
+		if v1.Type().Elem().Kind() == Uint8 && !s.countAll {
 			return bytealg.Equal(v1.Bytes(), v2.Bytes())
 		}
-		for i := 0; i < v1.Len(); i++ {
-			if !deepValueEqual(v1.Index(i), v2.Index(i), visited) {
-				return false
+		eq = true
+		for i := 0; i < v1.Len() || i < v2.Len(); i++ {
+			n := s.enterIndex(i)
+			var ok bool
+			switch {
+			case i >= v2.Len():
+				ok = s.missing(v1.Index(i), Value{})
+			case i >= v1.Len():
+				ok = s.missing(Value{}, v2.Index(i))
+			default:
+				ok = deepValueEqual(v1.Index(i), v2.Index(i), visited, s)
+			}
+			s.leave(n)
+			if !ok {
+				if !s.countAll {
+					return false
+				}
+				eq = false
 			}
 		}
-		return true
+		return eq
 	case Interface:
 		if v1.IsNil() || v2.IsNil() {
-			return v1.IsNil() == v2.IsNil()
+			return s.compared(v1.IsNil() == v2.IsNil())
 		}
-		return deepValueEqual(v1.Elem(), v2.Elem(), visited)
+		return deepValueEqual(v1.Elem(), v2.Elem(), visited, s)
 	case Pointer:
 		if v1.UnsafePointer() == v2.UnsafePointer() {
-			return true
+			return s.compared(true)
 		}
-		return deepValueEqual(v1.Elem(), v2.Elem(), visited)
+		return deepValueEqual(v1.Elem(), v2.Elem(), visited, s)
 	case Struct:
-		for i, n := 0, v1.NumField(); i < n; i++ {
-			if !deepValueEqual(v1.Field(i), v2.Field(i), visited) {
-				return false
+		eq = true
+		for i, nf := 0, v1.NumField(); i < nf; i++ {
+			n := s.enterField(v1.Type(), i)
+			ok := deepValueEqual(v1.Field(i), v2.Field(i), visited, s)
+			s.leave(n)
+			if !ok {
+				if !s.countAll {
+					return false
+				}
+				eq = false
 			}
 		}
-		return true
+		return eq
 	case Map:
 		if v1.IsNil() != v2.IsNil() {
-			return false
+			return s.compared(false)
 		}
-		if v1.Len() != v2.Len() {
-			return false
+		if v1.Len() != v2.Len() && !s.recordDiffs {
+			return s.compared(false)
 		}
 		if v1.UnsafePointer() == v2.UnsafePointer() {
-			return true
+			return s.compared(true)
 		}
+		eq = true
 		for _, k := range v1.MapKeys() {
 			val1 := v1.MapIndex(k)
 			val2 := v2.MapIndex(k)
-			if !val1.IsValid() || !val2.IsValid() || !deepValueEqual(val1, val2, visited) {
-				return false
+			n := s.enterKey(k)
+			var ok bool
+			if !val1.IsValid() || !val2.IsValid() {
+				ok = s.missing(val1, val2)
+			} else {
+				ok = deepValueEqual(val1, val2, visited, s)
+			}
+			s.leave(n)
+			if !ok {
+				if !s.countAll {
+					return false
+				}
+				eq = false
 			}
 		}
-		return true
+		if s.recordDiffs {
+			// Record the keys found only in v2.
+			for _, k := range v2.MapKeys() {
+				if v1.MapIndex(k).IsValid() {
+					continue
+				}
+				n := s.enterKey(k)
+				s.missing(Value{}, v2.MapIndex(k))
+				s.leave(n)
+				eq = false
+			}
+		}
+		return eq
 	case Func:
-		if v1.IsNil() && v2.IsNil() {
-			return true
+		if s.opts.CompareFuncsByPointer {
+			return s.compared(v1.Pointer() == v2.Pointer())
 		}
 		// Can't do better than this:
-		return false
+		return s.compared(v1.IsNil() && v2.IsNil())
 	case Int, Int8, Int16, Int32, Int64:
-		return v1.Int() == v2.Int()
+		return s.compared(v1.Int() == v2.Int())
 	case Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
-		return v1.Uint() == v2.Uint()
+		return s.compared(v1.Uint() == v2.Uint())
 	case String:
-		return v1.String() == v2.String()
+		return s.compared(v1.String() == v2.String())
 	case Bool:
-		return v1.Bool() == v2.Bool()
+		return s.compared(v1.Bool() == v2.Bool())
 	case Float32, Float64:
-		return v1.Float() == v2.Float()
+		return s.compared(s.floatEqual(v1.Float(), v2.Float()))
 	case Complex64, Complex128:
-		return v1.Complex() == v2.Complex()
+		c1, c2 := v1.Complex(), v2.Complex()
+		return s.compared(s.floatEqual(real(c1), real(c2)) && s.floatEqual(imag(c1), imag(c2)))
 	default:
 		// Normal equality suffices
-		return valueInterface(v1, false) == valueInterface(v2, false)
+		return s.compared(valueInterface(v1, false) == valueInterface(v2, false))
+	}
+}
+
+// floatEqual reports whether the floating-point numbers a and b are
+// equal under the options of s.
+func (s *deepEqualState) floatEqual(a, b float64) bool {
+	if a == b {
+		return !s.opts.DistinguishSignedZero || math.Signbit(a) == math.Signbit(b)
 	}
+	return s.opts.NaNEqual && math.IsNaN(a) && math.IsNaN(b)
+}
+
+// iterEqual reports whether the entries e1 and e2 listed by an iterator
+// from DeepEqualOptions.Iterators are deeply equal, with the same
+// semantics as for map values.
+func (s *deepEqualState) iterEqual(e1, e2 []MapEntry, visited map[visit]bool) bool {
+	if len(e1) != len(e2) {
+		return s.compared(false)
+	}
+	m2 := make(map[any]Value, len(e2))
+	for _, e := range e2 {
+		m2[valueInterface(e.Key, false)] = e.Value
+	}
+	eq := true
+	for _, e := range e1 {
+		val2, found := m2[valueInterface(e.Key, false)]
+		var ok bool
+		if !found {
+			ok = s.compared(false)
+		} else {
+			ok = deepValueEqual(e.Value, val2, visited, s)
+		}
+		if !ok {
+			if !s.countAll {
+				return false
+			}
+			eq = false
+		}
+	}
+	return eq
 }
 
 // DeepEqual reports whether x and y are “deeply equal,” defined as follows.
@@ -254,5 +652,87 @@ func DeepEqual(x, y any) bool {
 	if v1.Type() != v2.Type() {
 		return false
 	}
-	return deepValueEqual(v1, v2, make(map[visit]bool))
+	return deepValueEqual(v1, v2, make(map[visit]bool), &deepEqualState{opts: &defaultDeepEqualOptions})
+}
+
+var defaultDeepEqualOptions DeepEqualOptions
+
+// DeepEqualFunc reports whether x and y are “deeply equal” as defined by
+// DeepEqual, with the comparison adjusted by opts.
+func DeepEqualFunc(x, y any, opts DeepEqualOptions) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	v1 := ValueOf(x)
+	v2 := ValueOf(y)
+	if v1.Type() != v2.Type() {
+		return false
+	}
+	return deepValueEqual(v1, v2, make(map[visit]bool), newDeepEqualState(&opts))
+}
+
+// DeepEqualStats is like DeepEqual but does not stop at the first
+// difference, and additionally reports how many comparisons were made
+// and how many of them found the values unequal. It gives a measure of
+// how similar x and y are, for example for shrinking test inputs.
+//
+// A comparison is counted wherever DeepEqual would decide equality
+// without looking further into the values: comparing two numbers,
+// strings, bools, channels, or funcs; finding two slices or maps of
+// different lengths, or only one of them nil; finding two references
+// that are identical or that are already being compared. The elements
+// of byte slices are compared and counted individually.
+func DeepEqualStats(x, y any) (equal bool, comparisons, mismatches int) {
+	if x == nil || y == nil {
+		equal = x == y
+		if !equal {
+			mismatches = 1
+		}
+		return equal, 1, mismatches
+	}
+	v1 := ValueOf(x)
+	v2 := ValueOf(y)
+	s := &deepEqualState{opts: &defaultDeepEqualOptions, countAll: true}
+	equal = deepValueEqual(v1, v2, make(map[visit]bool), s)
+	return equal, s.comparisons, s.mismatches
+}
+
+// A Difference describes a pair of values found unequal by DeepEqualAll.
+type Difference struct {
+	// Path locates the values within the values passed to
+	// DeepEqualAll, as a sequence of Go selectors and indexes such as
+	// `.Items[2].Tags["color"]`. Pointers and interfaces are followed
+	// without adding to the path. Map keys other than strings, numbers
+	// and bools are shown by their type, as in "[main.Point key]". The
+	// Path of the values passed to DeepEqualAll themselves is empty.
+	Path string
+
+	// X and Y are the unequal values. If only one of them exists, such
+	// as an element beyond the end of the shorter of two slices or the
+	// value of a key in only one of two maps, the other is the zero
+	// Value.
+	X, Y Value
+}
+
+// DeepEqualAll is like DeepEqual but reports every difference between x
+// and y, in the order it finds them, rather than whether there are any.
+// It returns no differences if x and y are deeply equal.
+//
+// A difference is reported wherever DeepEqual would decide equality
+// without looking further into the values, as counted by
+// DeepEqualStats, except that slices and maps of different lengths are
+// compared element by element, with each element or key present in only
+// one of them reported as a difference. As with DeepEqual, references
+// that are already being compared are taken to be equal, so shared or
+// cyclic data is examined only once.
+func DeepEqualAll(x, y any) []Difference {
+	if x == nil || y == nil {
+		if x == y {
+			return nil
+		}
+		return []Difference{{X: ValueOf(x), Y: ValueOf(y)}}
+	}
+	s := &deepEqualState{opts: &defaultDeepEqualOptions, countAll: true, recordDiffs: true}
+	deepValueEqual(ValueOf(x), ValueOf(y), make(map[visit]bool), s)
+	return s.diffs
 }
@@ -51,6 +51,8 @@ var AtomicFields = []uintptr{
 	unsafe.Offsetof(mstats{}.last_gc_nanotime),
 	unsafe.Offsetof(mstats{}.last_gc_unix),
 	unsafe.Offsetof(mstats{}.gcPauseDist),
+	unsafe.Offsetof(mstats{}.gcPauseRecent),
+	unsafe.Offsetof(mstats{}.gcPauseRecentN),
 	unsafe.Offsetof(ticksType{}.val),
 	unsafe.Offsetof(workType{}.bytesMarked),
 	unsafe.Offsetof(timeHistogram{}.counts),
@@ -57,6 +57,19 @@ func NumGoroutine() int {
 	return int(gcount())
 }
 
+// MID returns the ID of the M, that is the operating system thread,
+// that is running the calling goroutine. It is meant for diagnostics,
+// such as correlating events recorded by a tracing tool with kernel
+// traces of the same thread.
+//
+// Unless the goroutine has called LockOSThread, it may move to another
+// M at any time, even before MID returns. The runtime parks idle Ms and
+// reuses them later, so an ID may appear again for unrelated work. IDs
+// are not the operating system's thread IDs.
+func MID() int64 {
+	return getg().m.id
+}
+
 //go:linkname debug_modinfo runtime/debug.modinfo
 func debug_modinfo() string {
 	return modinfo
@@ -85,10 +98,10 @@ func debug_modinfo() string {
 //go:linkname mayMoreStackPreempt
 func mayMoreStackPreempt() {
 	// Don't do anything on the g0 or gsignal stack.
-	g := getg()
-	if g == g.m.g0 || g == g.m.gsignal {
+	if onSystemStack() {
 		return
 	}
+	g := getg()
 	// Force a preemption, unless the stack is already poisoned.
 	if g.stackguard0 < stackPoisonMin {
 		g.stackguard0 = stackPreempt
@@ -104,10 +117,10 @@ func mayMoreStackPreempt() {
 //go:linkname mayMoreStackMove
 func mayMoreStackMove() {
 	// Don't do anything on the g0 or gsignal stack.
-	g := getg()
-	if g == g.m.g0 || g == g.m.gsignal {
+	if onSystemStack() {
 		return
 	}
+	g := getg()
 	// Force stack movement, unless the stack is already poisoned.
 	if g.stackguard0 < stackPoisonMin {
 		g.stackguard0 = stackForceMove
@@ -202,6 +202,40 @@ func MemclrBytes(b []byte) {
 	memclrNoHeapPointers(s.array, uintptr(s.len))
 }
 
+func MemclrBytesChunked(b []byte) {
+	s := (*slice)(unsafe.Pointer(&b))
+	memclrNoHeapPointersChunked(uintptr(s.len), s.array)
+}
+
+const MemclrChunkBytes = memclrChunkBytes
+
+// SetSyncSpin sets the sync.Mutex spin limit, as GODEBUG=syncspin=n
+// does, and returns the previous limit.
+func SetSyncSpin(n int32) int32 {
+	old := debug.syncspin
+	debug.syncspin = n
+	return old
+}
+
+// SetSliceGrowthThreshold sets the capacity below which append doubles
+// slices, as GODEBUG=slicegrowththreshold=n does, and returns the
+// previous setting.
+func SetSliceGrowthThreshold(n int32) int32 {
+	old := debug.slicegrowththreshold
+	debug.slicegrowththreshold = n
+	return old
+}
+
+// OnSystemStack reports what onSystemStack returns on the user stack
+// and within systemstack.
+func OnSystemStack() (user, system bool) {
+	user = onSystemStack()
+	systemstack(func() {
+		system = onSystemStack()
+	})
+	return
+}
+
 const HashLoad = hashLoad
 
 // entry point for testing
@@ -1490,6 +1524,8 @@ func (l *GCCPULimiter) ResetCapacity(now int64, nprocs int32) {
 
 const ScavengePercent = scavengePercent
 
+const GCPauseRecentLen = gcPauseRecentLen
+
 type Scavenger struct {
 	Sleep      func(int64) int64
 	Scavenge   func(uintptr) (uintptr, int64)
@@ -1616,3 +1652,34 @@ func (s *ScavengeIndex) Mark(base, limit uintptr) {
 func (s *ScavengeIndex) Clear(ci ChunkIdx) {
 	s.i.clear(chunkIdx(ci))
 }
+
+const MakesliceHookEnabled = makesliceHookEnabled
+
+var SetMakesliceHook = setMakesliceHook
+
+const PinTimeEnabled = pinTimeEnabled
+
+func ProcPin() int { return procPin() }
+func ProcUnpin()   { procUnpin() }
+
+func ProcPinBounded(maxNanos int64) (int, bool) { return procPinBounded(maxNanos) }
+func ProcUnpinBounded()                         { procUnpinBounded() }
+func ProcPinExpired() bool                      { return procPinExpired() }
+
+// SetMapSeedForTest fixes the hash seed of maps that the runtime creates
+// or clears from now on, and the starting point of every map iteration,
+// so that iterating maps built by the same operations visits their
+// entries in the same order within this process. It is for tests only
+// and must never be used in production, where random seeds protect
+// against hash flooding. Maps allocated on the stack by the compiler
+// still get a random seed. ClearMapSeedForTest undoes it.
+func SetMapSeedForTest(seed uintptr) {
+	mapSeedValue = seed
+	mapSeedFixed = true
+}
+
+// ClearMapSeedForTest restores random map seeds.
+func ClearMapSeedForTest() {
+	mapSeedFixed = false
+	mapSeedValue = 0
+}
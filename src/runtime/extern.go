@@ -148,6 +148,21 @@ It is a comma-separated list of name=val pairs setting these named variables:
 	schedtrace: setting schedtrace=X causes the scheduler to emit a single line to standard
 	error every X milliseconds, summarizing the scheduler state.
 
+	slicegrowththreshold: setting slicegrowththreshold=N sets the capacity, in
+	elements, below which append doubles the capacity of a full slice. Beyond it,
+	growth gradually slows to 1.25x for large slices. The default is 256. Larger
+	values make appends to mid-sized slices reallocate and copy less often but
+	leave more unused capacity behind. This setting is experimental and may be
+	removed; it is meant for measuring the effect of the growth policy on real
+	workloads.
+
+	syncspin: setting syncspin=N sets the number of times a goroutine blocked in
+	sync.Mutex or sync.RWMutex Lock may actively spin, waiting for the lock
+	to be released, before it parks. The default is 4. Larger values can reduce
+	parking under brief contention on machines with many cores, at the cost of
+	CPU time. The setting applies to all mutexes in the process;
+	syncspin=0 disables spinning.
+
 	tracebackancestors: setting tracebackancestors=N extends tracebacks with the stacks at
 	which goroutines were created, where N limits the number of ancestor goroutines to
 	report. This also extends the information returned by runtime.Stack. Ancestor's goroutine
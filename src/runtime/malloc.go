@@ -1182,6 +1182,12 @@ func mallocgc(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 	return x
 }
 
+// memclrChunkBytes is the size of the chunks cleared by
+// memclrNoHeapPointersChunked between preemption checks.
+// Clears no larger than this gain nothing from chunking.
+// got this from benchmarking. 128k is too small, 512k is too large.
+const memclrChunkBytes = 256 * 1024
+
 // memclrNoHeapPointersChunked repeatedly calls memclrNoHeapPointers
 // on chunks of the buffer to be zeroed, with opportunities for preemption
 // along the way.  memclrNoHeapPointers contains no safepoints and also
@@ -1192,18 +1198,16 @@ func mallocgc(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 // pointers, this allows the GC to run before it is all cleared.
 func memclrNoHeapPointersChunked(size uintptr, x unsafe.Pointer) {
 	v := uintptr(x)
-	// got this from benchmarking. 128k is too small, 512k is too large.
-	const chunkBytes = 256 * 1024
 	vsize := v + size
-	for voff := v; voff < vsize; voff = voff + chunkBytes {
+	for voff := v; voff < vsize; voff = voff + memclrChunkBytes {
 		if getg().preempt {
 			// may hold locks, e.g., profiling
 			goschedguarded()
 		}
 		// clear min(avail, lump) bytes
 		n := vsize - voff
-		if n > chunkBytes {
-			n = chunkBytes
+		if n > memclrChunkBytes {
+			n = memclrChunkBytes
 		}
 		memclrNoHeapPointers(unsafe.Pointer(voff), n)
 	}
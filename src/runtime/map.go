@@ -305,6 +305,22 @@ func (h *hmap) createOverflow() {
 	}
 }
 
+// If mapSeedFixed is set, mapSeedValue replaces the random hash seed
+// of maps and the random starting point of map iteration. It is only
+// set by tests; see SetMapSeedForTest in export_test.go.
+var (
+	mapSeedFixed bool
+	mapSeedValue uintptr
+)
+
+// mapHashSeed returns the hash seed for a new or emptied map.
+func mapHashSeed() uint32 {
+	if mapSeedFixed {
+		return uint32(mapSeedValue)
+	}
+	return fastrand()
+}
+
 func makemap64(t *maptype, hint int64, h *hmap) *hmap {
 	if int64(int(hint)) != hint { // note 见过好几个这种操作了，只允许大小为int，int的长度取决于架构，不过通常应该是64位啦，只是需要兼容特殊情况
 		hint = 0
@@ -317,7 +333,7 @@ func makemap64(t *maptype, hint int64, h *hmap) *hmap {
 // at compile time and the map needs to be allocated on the heap.
 func makemap_small() *hmap {
 	h := new(hmap)
-	h.hash0 = fastrand()
+	h.hash0 = mapHashSeed()
 	return h
 }
 
@@ -336,7 +352,7 @@ func makemap(t *maptype, hint int, h *hmap) *hmap {
 	if h == nil {
 		h = new(hmap)
 	}
-	h.hash0 = fastrand()
+	h.hash0 = mapHashSeed()
 
 	// Find the size parameter B which will hold the requested # of elements.
 	// For hint < 0 overLoadFactor returns false since hint < bucketCnt.
@@ -825,7 +841,7 @@ search:
 			// Reset the hash seed to make it more difficult for attackers to
 			// repeatedly trigger hash collisions. See issue 25237.
 			if h.count == 0 {
-				h.hash0 = fastrand()
+				h.hash0 = mapHashSeed()
 			}
 			break search
 		}
@@ -877,6 +893,9 @@ func mapiterinit(t *maptype, h *hmap, it *hiter) {
 	} else {
 		r = uintptr(fastrand())
 	}
+	if mapSeedFixed {
+		r = mapSeedValue
+	}
 	it.startBucket = r & bucketMask(h.B)
 	it.offset = uint8(r >> h.B & (bucketCnt - 1))
 
@@ -1044,7 +1063,7 @@ func mapclear(t *maptype, h *hmap) {
 
 	// Reset the hash seed to make it more difficult for attackers to
 	// repeatedly trigger hash collisions. See issue 25237.
-	h.hash0 = fastrand()
+	h.hash0 = mapHashSeed()
 
 	// Keep the mapextra allocation but clear any extra information.
 	if h.extra != nil {
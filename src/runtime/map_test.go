@@ -537,6 +537,33 @@ func TestMapIterOrder(t *testing.T) {
 	}
 }
 
+var mapSeedSink map[int]bool
+
+func TestMapSeedForTest(t *testing.T) {
+	runtime.SetMapSeedForTest(12345)
+	defer runtime.ClearMapSeedForTest()
+
+	ord := func() []int {
+		// Let m escape so that the runtime allocates it.
+		m := make(map[int]bool)
+		mapSeedSink = m
+		for i := 0; i < 100; i++ {
+			m[i] = true
+		}
+		var s []int
+		for key := range m {
+			s = append(s, key)
+		}
+		return s
+	}
+	first := ord()
+	for try := 0; try < 10; try++ {
+		if s := ord(); !reflect.DeepEqual(first, s) {
+			t.Fatalf("map iteration order changed with a fixed seed:\n%v\n%v", first, s)
+		}
+	}
+}
+
 // Issue 8410
 func TestMapSparseIterOrder(t *testing.T) {
 	// Run several rounds to increase the probability
@@ -379,6 +379,35 @@ func TestMemclr(t *testing.T) {
 	}
 }
 
+func TestMemclrChunked(t *testing.T) {
+	for _, n := range []int{0, 1, MemclrChunkBytes - 1, MemclrChunkBytes, 2*MemclrChunkBytes + 13} {
+		mem := make([]byte, n+2)
+		for i := range mem {
+			mem[i] = 0xee
+		}
+		MemclrBytesChunked(mem[1 : n+1])
+		if mem[0] != 0xee || mem[n+1] != 0xee {
+			t.Fatalf("n=%d: overwrite outside cleared range", n)
+		}
+		for i := 1; i <= n; i++ {
+			if mem[i] != 0 {
+				t.Fatalf("n=%d: failed clear mem[%d] = %d", n, i, mem[i])
+			}
+		}
+	}
+
+	// Growing a large pointer-free slice clears the new capacity
+	// in chunks; the part past the new length must still be zero.
+	s := make([]byte, 1, 1)
+	s = append(s, make([]byte, 3*MemclrChunkBytes)...)
+	s = s[:cap(s)]
+	for i := 1; i < len(s); i++ {
+		if s[i] != 0 {
+			t.Fatalf("append: s[%d] = %d, want 0", i, s[i])
+		}
+	}
+}
+
 func BenchmarkMemclr(b *testing.B) {
 	for _, n := range []int{5, 16, 64, 256, 4096, 65536} {
 		x := make([]byte, n)
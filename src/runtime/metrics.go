@@ -112,6 +112,20 @@ func initMetrics() {
 				out.scalar = in.sysStats.gcCyclesDone
 			},
 		},
+		"/gc/finalizers/pending:objects": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				// Load finRun first so that the result can't underflow.
+				run := finRun.Load()
+				out.scalar = finQueued.Load() - run
+			},
+		},
+		"/gc/finalizers/queued:objects": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = finQueued.Load()
+			},
+		},
 		"/gc/heap/allocs-by-size:bytes": {
 			deps: makeStatDepSet(heapStatsDep),
 			compute: func(in *statAggregate, out *metricValue) {
@@ -203,6 +217,24 @@ func initMetrics() {
 				}
 			},
 		},
+		"/gc/pauses-recent:seconds": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				hist := out.float64HistOrInit(timeHistBuckets)
+				// Rebuild the distribution from the samples currently in
+				// the window. This may race with a new pause being recorded,
+				// which is fine since the window is approximate anyway.
+				gcPauseRecentDist = timeHistogram{}
+				n := atomic.Load64(&memstats.gcPauseRecentN)
+				if n > gcPauseRecentLen {
+					n = gcPauseRecentLen
+				}
+				for i := uint64(0); i < n; i++ {
+					gcPauseRecentDist.record(int64(atomic.Load64(&memstats.gcPauseRecent[i])))
+				}
+				hist.counts[0] = gcPauseRecentDist.underflow
+				copy(hist.counts[1:], gcPauseRecentDist.counts[:])
+			},
+		},
 		"/gc/stack/starting-size:bytes": {
 			compute: func(in *statAggregate, out *metricValue) {
 				out.kind = metricKindUint64
@@ -318,6 +350,12 @@ func initMetrics() {
 				out.scalar = uint64(gomaxprocs)
 			},
 		},
+		"/sched/goroutines/max-stack:bytes": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = uint64(maxGoroutineStack())
+			},
+		},
 		"/sched/goroutines:goroutines": {
 			compute: func(_ *statAggregate, out *metricValue) {
 				out.kind = metricKindUint64
@@ -333,6 +371,40 @@ func initMetrics() {
 				}
 			},
 		},
+		"/sched/pinned-time:cpu-seconds": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindFloat64
+				out.scalar = float64bits(float64(readPinnedTime()) / 1e9)
+			},
+		},
+		"/sched/timers/active:timers": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = activeTimers()
+			},
+		},
+		"/sync/mutex/wait-total:seconds": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindFloat64
+				out.scalar = float64bits(float64(mutexWaitTime.Load()) / 1e9)
+			},
+		},
+		"/sync/pool/retained-bytes:bytes": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				if poolstats != nil {
+					_, out.scalar = poolstats()
+				}
+			},
+		},
+		"/sync/pool/retained-objects:objects": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				if poolstats != nil {
+					out.scalar, _ = poolstats()
+				}
+			},
+		},
 	}
 	metricsInit = true
 }
@@ -575,6 +647,10 @@ type metricFloat64Histogram struct {
 	buckets []float64
 }
 
+// gcPauseRecentDist is scratch space used to compute the
+// /gc/pauses-recent:seconds metric, and is protected by metricsSema.
+var gcPauseRecentDist timeHistogram
+
 // agg is used by readMetrics, and is protected by metricsSema.
 //
 // Managed as a global variable because its pointer will be
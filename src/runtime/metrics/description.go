@@ -82,6 +82,18 @@ var allDesc = []Description{
 		Kind:        KindUint64,
 		Cumulative:  true,
 	},
+	{
+		Name: "/gc/finalizers/pending:objects",
+		Description: "Number of objects whose finalizers have been queued for execution but have not yet " +
+			"finished running. A steadily growing value indicates that the finalizer goroutine is falling behind.",
+		Kind: KindUint64,
+	},
+	{
+		Name:        "/gc/finalizers/queued:objects",
+		Description: "Cumulative count of objects whose finalizers were queued for execution after the garbage collector found them unreachable.",
+		Kind:        KindUint64,
+		Cumulative:  true,
+	},
 	{
 		Name: "/gc/heap/allocs-by-size:bytes",
 		Description: "Distribution of heap allocations by approximate size. " +
@@ -162,6 +174,15 @@ var allDesc = []Description{
 		Kind:        KindFloat64Histogram,
 		Cumulative:  true,
 	},
+	{
+		Name: "/gc/pauses-recent:seconds",
+		Description: "Distribution of individual GC-related stop-the-world pause latencies " +
+			"over approximately the last 256 GC cycles. Older pauses are discarded as new " +
+			"ones are recorded, so unlike /gc/pauses:seconds this metric reflects only " +
+			"recent behavior.",
+		Kind:       KindFloat64Histogram,
+		Cumulative: false,
+	},
 	{
 		Name:        "/gc/stack/starting-size:bytes",
 		Description: "The stack size of new goroutines.",
@@ -247,6 +268,11 @@ var allDesc = []Description{
 		Description: "The current runtime.GOMAXPROCS setting, or the number of operating system threads that can execute user-level Go code simultaneously.",
 		Kind:        KindUint64,
 	},
+	{
+		Name:        "/sched/goroutines/max-stack:bytes",
+		Description: "Size of the largest stack of any live goroutine. This is a snapshot of the current maximum, not a high-water mark: it decreases when the goroutine with the largest stack exits or its stack shrinks.",
+		Kind:        KindUint64,
+	},
 	{
 		Name:        "/sched/goroutines:goroutines",
 		Description: "Count of live goroutines.",
@@ -257,6 +283,34 @@ var allDesc = []Description{
 		Description: "Distribution of the time goroutines have spent in the scheduler in a runnable state before actually running.",
 		Kind:        KindFloat64Histogram,
 	},
+	{
+		Name:        "/sched/pinned-time:cpu-seconds",
+		Description: "Estimated total time goroutines have spent pinned to their P, with preemption disabled, by the runtime's procPin. Only measured when the runtime is built with the pintime build tag; otherwise always zero.",
+		Kind:        KindFloat64,
+		Cumulative:  true,
+	},
+	{
+		Name:        "/sched/timers/active:timers",
+		Description: "Number of timers currently armed in the runtime's timer heaps, such as those created by time.AfterFunc, time.NewTimer and context.WithTimeout. This is a snapshot: a count that keeps growing may indicate timers that are never stopped, for example because a context's CancelFunc is not called.",
+		Kind:        KindUint64,
+		Cumulative:  false,
+	},
+	{
+		Name:        "/sync/mutex/wait-total:seconds",
+		Description: "Approximate cumulative time goroutines have spent blocked on a sync.Mutex or sync.RWMutex since the program started.",
+		Kind:        KindFloat64,
+		Cumulative:  true,
+	},
+	{
+		Name:        "/sync/pool/retained-bytes:bytes",
+		Description: "Approximate memory retained by items in all sync.Pools, including their victim caches, as reported by the pools' SizeOf functions. Pools without a SizeOf function do not contribute.",
+		Kind:        KindUint64,
+	},
+	{
+		Name:        "/sync/pool/retained-objects:objects",
+		Description: "Number of items retained by all sync.Pools, including their victim caches.",
+		Kind:        KindUint64,
+	},
 }
 
 // All returns a slice of containing metric descriptions for all supported metrics.
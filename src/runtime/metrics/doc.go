@@ -63,6 +63,15 @@ Below is the full list of supported metrics, ordered lexicographically.
 	/gc/cycles/total:gc-cycles
 		Count of all completed GC cycles.
 
+	/gc/finalizers/pending:objects
+		Number of objects whose finalizers have been queued for execution
+		but have not yet finished running. A steadily growing value
+		indicates that the finalizer goroutine is falling behind.
+
+	/gc/finalizers/queued:objects
+		Cumulative count of objects whose finalizers were queued for
+		execution after the garbage collector found them unreachable.
+
 	/gc/heap/allocs-by-size:bytes
 		Distribution of heap allocations by approximate size.
 		Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects,
@@ -109,6 +118,12 @@ Below is the full list of supported metrics, ordered lexicographically.
 		time gets too high. This is most likely to occur with use of SetMemoryLimit.
 		The first GC cycle is cycle 1, so a value of 0 indicates that it was never enabled.
 
+	/gc/pauses-recent:seconds
+		Distribution of individual GC-related stop-the-world pause latencies
+		over approximately the last 256 GC cycles. Older pauses are
+		discarded as new ones are recorded, so unlike /gc/pauses:seconds
+		this metric reflects only recent behavior.
+
 	/gc/pauses:seconds
 		Distribution individual GC-related stop-the-world pause latencies.
 
@@ -182,12 +197,44 @@ Below is the full list of supported metrics, ordered lexicographically.
 		operating system threads that can execute user-level Go code
 		simultaneously.
 
+	/sched/goroutines/max-stack:bytes
+		Size of the largest stack of any live goroutine. This is a
+		snapshot of the current maximum, not a high-water mark: it
+		decreases when the goroutine with the largest stack exits or
+		its stack shrinks.
+
 	/sched/goroutines:goroutines
 		Count of live goroutines.
 
 	/sched/latencies:seconds
 		Distribution of the time goroutines have spent in the scheduler
 		in a runnable state before actually running.
+
+	/sched/pinned-time:cpu-seconds
+		Estimated total time goroutines have spent pinned to their P,
+		with preemption disabled, by the runtime's procPin. Only
+		measured when the runtime is built with the pintime build tag;
+		otherwise always zero.
+
+	/sched/timers/active:timers
+		Number of timers currently armed in the runtime's timer heaps,
+		such as those created by time.AfterFunc, time.NewTimer and
+		context.WithTimeout. This is a snapshot: a count that keeps
+		growing may indicate timers that are never stopped, for example
+		because a context's CancelFunc is not called.
+
+	/sync/mutex/wait-total:seconds
+		Approximate cumulative time goroutines have spent blocked on a
+		sync.Mutex or sync.RWMutex since the program started.
+
+	/sync/pool/retained-bytes:bytes
+		Approximate memory retained by items in all sync.Pools,
+		including their victim caches, as reported by the pools' SizeOf
+		functions. Pools without a SizeOf function do not contribute.
+
+	/sync/pool/retained-objects:objects
+		Number of items retained by all sync.Pools, including their
+		victim caches.
 */
 /*
 note 包指标提供了一个稳定的接口来访问由 Go 运行时导出的实现定义的指标。
@@ -178,8 +178,9 @@ func TestReadMetricsConsistency(t *testing.T) {
 		total, totalBytes       uint64
 	}
 	var gc struct {
-		numGC  uint64
-		pauses uint64
+		numGC        uint64
+		pauses       uint64
+		recentPauses uint64
 	}
 	for i := range samples {
 		kind := samples[i].Value.Kind()
@@ -225,6 +226,12 @@ func TestReadMetricsConsistency(t *testing.T) {
 			for i := range h.Counts {
 				gc.pauses += h.Counts[i]
 			}
+		case "/gc/pauses-recent:seconds":
+			h := samples[i].Value.Float64Histogram()
+			gc.recentPauses = 0
+			for i := range h.Counts {
+				gc.recentPauses += h.Counts[i]
+			}
 		case "/sched/gomaxprocs:threads":
 			if got, want := samples[i].Value.Uint64(), uint64(runtime.GOMAXPROCS(-1)); got != want {
 				t.Errorf("gomaxprocs doesn't match runtime.GOMAXPROCS: got %d, want %d", got, want)
@@ -233,6 +240,10 @@ func TestReadMetricsConsistency(t *testing.T) {
 			if samples[i].Value.Uint64() < 1 {
 				t.Error("number of goroutines is less than one")
 			}
+		case "/sched/goroutines/max-stack:bytes":
+			if samples[i].Value.Uint64() == 0 {
+				t.Error("largest goroutine stack is empty")
+			}
 		}
 	}
 	if totalVirtual.got != totalVirtual.want {
@@ -289,6 +300,10 @@ func TestReadMetricsConsistency(t *testing.T) {
 	if gc.pauses < gc.numGC*2 {
 		t.Errorf("fewer pauses than expected: got %d, want at least %d", gc.pauses, gc.numGC*2)
 	}
+	// The recent pauses are a bounded window over all pauses.
+	if gc.recentPauses == 0 || gc.recentPauses > runtime.GCPauseRecentLen {
+		t.Errorf("unexpected number of recent pauses: got %d, want between 1 and %d", gc.recentPauses, runtime.GCPauseRecentLen)
+	}
 }
 
 func BenchmarkReadMetricsLatency(b *testing.B) {
@@ -411,3 +426,146 @@ func TestReadMetricsCumulative(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestPinnedTimeMetric(t *testing.T) {
+	s := []metrics.Sample{{Name: "/sched/pinned-time:cpu-seconds"}}
+	metrics.Read(s)
+	before := s[0].Value.Float64()
+
+	const pin = 5 * time.Millisecond
+	runtime.ProcPin()
+	for start := time.Now(); time.Since(start) < pin; {
+	}
+	runtime.ProcUnpin()
+
+	metrics.Read(s)
+	got := s[0].Value.Float64() - before
+	if !runtime.PinTimeEnabled {
+		if s[0].Value.Float64() != 0 {
+			t.Errorf("pinned time = %f without the pintime build tag, want 0", s[0].Value.Float64())
+		}
+		return
+	}
+	if got < pin.Seconds() {
+		t.Errorf("pinned time grew by %fs while pinned for %v", got, pin)
+	}
+}
+
+func TestFinalizerMetrics(t *testing.T) {
+	s := []metrics.Sample{
+		{Name: "/gc/finalizers/queued:objects"},
+		{Name: "/gc/finalizers/pending:objects"},
+	}
+	metrics.Read(s)
+	queuedBefore := s[0].Value.Uint64()
+
+	// Queue a batch of finalizers behind one that blocks, so that
+	// the finalizer goroutine falls behind.
+	const n = 10
+	started := make(chan bool, n)
+	release := make(chan bool)
+	for i := 0; i < n; i++ {
+		runtime.SetFinalizer(new([64]byte), func(*[64]byte) {
+			started <- true
+			<-release
+		})
+	}
+	runtime.GC()
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("finalizer did not run")
+	}
+
+	metrics.Read(s)
+	if got := s[0].Value.Uint64() - queuedBefore; got < 1 {
+		t.Errorf("queued finalizers grew by %d, want at least 1", got)
+	}
+	if got := s[1].Value.Uint64(); got < 1 {
+		t.Errorf("pending finalizers = %d while a finalizer is blocked, want at least 1", got)
+	}
+	close(release)
+
+	for i := 1; i < n; i++ {
+		runtime.GC()
+		<-started
+	}
+	metrics.Read(s)
+	if got := s[0].Value.Uint64() - queuedBefore; got < n {
+		t.Errorf("queued finalizers grew by %d, want at least %d", got, n)
+	}
+}
+
+func TestMaxStackMetric(t *testing.T) {
+	s := []metrics.Sample{{Name: "/sched/goroutines/max-stack:bytes"}}
+
+	// Grow a goroutine's stack to at least 1 MiB and keep it there.
+	const deep = 1 << 20
+	grown := make(chan bool)
+	release := make(chan bool)
+	var recurse func(n int, pad [1024]byte) byte
+	recurse = func(n int, pad [1024]byte) byte {
+		if n > 0 {
+			return recurse(n-1, pad) + pad[n%len(pad)]
+		}
+		grown <- true
+		<-release
+		return 0
+	}
+	go recurse(deep/1024, [1024]byte{})
+	<-grown
+	metrics.Read(s)
+	if got := s[0].Value.Uint64(); got < deep {
+		t.Errorf("largest stack = %d bytes with a goroutine %d bytes deep, want at least %d", got, deep, deep)
+	}
+	close(release)
+}
+
+func TestMutexWaitMetric(t *testing.T) {
+	s := []metrics.Sample{{Name: "/sync/mutex/wait-total:seconds"}}
+	metrics.Read(s)
+	before := s[0].Value.Float64()
+
+	// Hold a mutex while another goroutine blocks on it.
+	const hold = 50 * time.Millisecond
+	var mu sync.Mutex
+	mu.Lock()
+	done := make(chan bool)
+	go func() {
+		mu.Lock()
+		mu.Unlock()
+		close(done)
+	}()
+	time.Sleep(hold)
+	mu.Unlock()
+	<-done
+
+	metrics.Read(s)
+	if got := s[0].Value.Float64() - before; got < (hold / 2).Seconds() {
+		t.Errorf("mutex wait time grew by %fs with a goroutine blocked for %v", got, hold)
+	}
+}
+
+func TestActiveTimersMetric(t *testing.T) {
+	s := []metrics.Sample{{Name: "/sched/timers/active:timers"}}
+	metrics.Read(s)
+	before := s[0].Value.Uint64()
+
+	const n = 100
+	timers := make([]*time.Timer, n)
+	for i := range timers {
+		timers[i] = time.AfterFunc(time.Hour, func() {})
+	}
+	metrics.Read(s)
+	if got := s[0].Value.Uint64(); got < before+n {
+		t.Errorf("active timers = %d with %d new timers armed, want at least %d", got, n, before+n)
+	}
+
+	for _, tm := range timers {
+		tm.Stop()
+	}
+	metrics.Read(s)
+	if got := s[0].Value.Uint64(); got >= before+n {
+		t.Errorf("active timers = %d after stopping %d timers, want less than %d", got, n, before+n)
+	}
+}
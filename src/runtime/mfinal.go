@@ -38,6 +38,11 @@ var fingwait bool
 var fingwake bool
 var allfin *finblock // list of all blocks
 
+// finQueued and finRun count the finalizers queued for execution and
+// the finalizers run by the finalizer goroutine, respectively.
+// Their difference is the number of finalizers still waiting to run.
+var finQueued, finRun atomic.Uint64
+
 // NOTE: Layout known to queuefinalizer.
 type finalizer struct {
 	fn   *funcval       // function to call (may be a heap pointer)
@@ -120,6 +125,7 @@ func queuefinalizer(p unsafe.Pointer, fn *funcval, nret uintptr, fint *_type, ot
 	f.fint = fint
 	f.ot = ot
 	f.arg = p
+	finQueued.Add(1)
 	fingwake = true
 	unlock(&finlock)
 }
@@ -241,6 +247,7 @@ func runfinq() {
 				fingRunning = true
 				reflectcall(nil, unsafe.Pointer(f.fn), frame, uint32(framesz), uint32(framesz), uint32(framesz), &regs)
 				fingRunning = false
+				finRun.Add(1)
 
 				// Drop finalizer queue heap references
 				// before hiding them from markroot.
@@ -460,6 +467,44 @@ okarg:
 	})
 }
 
+// sync_runtime_checkFinalizer returns why SetFinalizer would throw if
+// asked to set a finalizer on obj, or "" if it would not, so that
+// Pool.Put can report a misused Finalizer with a panic instead.
+//
+//go:linkname sync_runtime_checkFinalizer sync.runtime_checkFinalizer
+func sync_runtime_checkFinalizer(obj any) string {
+	e := efaceOf(&obj)
+	etyp := e._type
+	if etyp == nil {
+		return "first argument is nil"
+	}
+	if etyp.kind&kindMask != kindPtr {
+		return "first argument is " + etyp.string() + ", not pointer"
+	}
+	ot := (*ptrtype)(unsafe.Pointer(etyp))
+	base, _, _ := findObject(uintptr(e.data), 0, 0)
+	if base == 0 {
+		// Zero-sized and global objects are accepted and
+		// ignored; leave anything else to SetFinalizer.
+		return ""
+	}
+	if uintptr(e.data) != base {
+		// As in SetFinalizer, interior pointers are only
+		// accepted for tiny objects.
+		if ot.elem == nil || ot.elem.ptrdata != 0 || ot.elem.size >= maxTinySize {
+			return "pointer not at beginning of allocated block"
+		}
+	}
+	var set bool
+	systemstack(func() {
+		set = hasspecial(e.data, _KindSpecialFinalizer)
+	})
+	if set {
+		return "finalizer already set"
+	}
+	return ""
+}
+
 // Mark KeepAlive as noinline so that it is easily detectable as an intrinsic.
 //
 //go:noinline
@@ -730,7 +730,7 @@ func gcStart(trigger gcTrigger) {
 		now = startTheWorldWithSema(trace.enabled)
 		work.pauseNS += now - work.pauseStart
 		work.tMark = now
-		memstats.gcPauseDist.record(now - work.pauseStart)
+		memstats.recordGCPause(now - work.pauseStart)
 
 		// Release the CPU limiter.
 		gcCPULimiter.finishGCTransition(now)
@@ -881,7 +881,7 @@ top:
 		systemstack(func() {
 			now := startTheWorldWithSema(true)
 			work.pauseNS += now - work.pauseStart
-			memstats.gcPauseDist.record(now - work.pauseStart)
+			memstats.recordGCPause(now - work.pauseStart)
 		})
 		semrelease(&worldsema)
 		goto top
@@ -999,7 +999,7 @@ func gcMarkTermination() {
 	unixNow := sec*1e9 + int64(nsec)
 	work.pauseNS += now - work.pauseStart
 	work.tEnd = now
-	memstats.gcPauseDist.record(now - work.pauseStart)
+	memstats.recordGCPause(now - work.pauseStart)
 	atomic.Store64(&memstats.last_gc_unix, uint64(unixNow)) // must be Unix time to make sense to user
 	atomic.Store64(&memstats.last_gc_nanotime, uint64(now)) // monotonic time for us
 	memstats.pause_ns[memstats.numgc%uint32(len(memstats.pause_ns))] = uint64(work.pauseNS)
@@ -1572,6 +1572,7 @@ func gcResetMarkState() {
 // Hooks for other packages
 
 var poolcleanup func()
+var poolstats func() (objects, bytes uint64)
 var boringCaches []unsafe.Pointer // for crypto/internal/boring
 
 //go:linkname sync_runtime_registerPoolCleanup sync.runtime_registerPoolCleanup
@@ -1579,6 +1580,11 @@ func sync_runtime_registerPoolCleanup(f func()) {
 	poolcleanup = f
 }
 
+//go:linkname sync_runtime_registerPoolStats sync.runtime_registerPoolStats
+func sync_runtime_registerPoolStats(f func() (objects, bytes uint64)) {
+	poolstats = f
+}
+
 //go:linkname boring_registerCache crypto/internal/boring/bcache.registerCache
 func boring_registerCache(p unsafe.Pointer) {
 	boringCaches = append(boringCaches, p)
@@ -1832,6 +1832,32 @@ func removespecial(p unsafe.Pointer, kind uint8) *special {
 	return result
 }
 
+// hasspecial reports whether the object at p has a special record of
+// the given kind.
+func hasspecial(p unsafe.Pointer, kind uint8) bool {
+	span := spanOfHeap(uintptr(p))
+	if span == nil {
+		return false
+	}
+
+	mp := acquirem()
+	span.ensureSwept()
+
+	offset := uintptr(p) - span.base()
+
+	found := false
+	lock(&span.speciallock)
+	for s := span.specials; s != nil; s = s.next {
+		if offset == uintptr(s.offset) && kind == s.kind {
+			found = true
+			break
+		}
+	}
+	unlock(&span.speciallock)
+	releasem(mp)
+	return found
+}
+
 // The described object has a finalizer set for it.
 //
 // specialfinalizer is allocated from non-GC'd memory, so any heap
@@ -52,6 +52,29 @@ type mstats struct {
 	//
 	// Each individual pause is counted separately, unlike pause_ns.
 	gcPauseDist timeHistogram
+
+	// gcPauseRecent is a circular buffer of the most recent individual
+	// GC-related pause durations in nanoseconds, and gcPauseRecentN is
+	// the total number of pauses ever written to it. Both are updated
+	// atomically by recordGCPause.
+	gcPauseRecent  [gcPauseRecentLen]uint64
+	gcPauseRecentN uint64
+}
+
+// gcPauseRecentLen is the number of individual pauses retained by
+// memstats.gcPauseRecent. Each GC cycle has at least two pauses, so
+// this covers approximately the last 256 GC cycles.
+const gcPauseRecentLen = 512
+
+// recordGCPause records a single GC-related stop-the-world pause
+// of the given duration in nanoseconds.
+//
+//go:nosplit
+func (s *mstats) recordGCPause(duration int64) {
+	s.gcPauseDist.record(duration)
+	n := atomic.Load64(&s.gcPauseRecentN)
+	atomic.Store64(&s.gcPauseRecent[n%gcPauseRecentLen], uint64(duration))
+	atomic.Store64(&s.gcPauseRecentN, n+1)
 }
 
 var memstats mstats
@@ -338,6 +361,10 @@ func init() {
 		println(offset)
 		throw("memstats.gcPauseDist not aligned to 8 bytes")
 	}
+	if offset := unsafe.Offsetof(memstats.gcPauseRecent); offset%8 != 0 {
+		println(offset)
+		throw("memstats.gcPauseRecent not aligned to 8 bytes")
+	}
 	// Ensure the size of heapStatsDelta causes adjacent fields/slots (e.g.
 	// [3]heapStatsDelta) to be 8-byte aligned.
 	if size := unsafe.Sizeof(heapStatsDelta{}); size%8 != 0 {
@@ -0,0 +1,21 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !pintime
+
+package runtime
+
+const pinTimeEnabled = false
+
+type pinTimePerM struct{}
+
+//go:nosplit
+func pinTimeStart(mp *m) {}
+
+//go:nosplit
+func pinTimeEnd(mp *m) {}
+
+func readPinnedTime() int64 {
+	return 0
+}
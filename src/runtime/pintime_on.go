@@ -0,0 +1,47 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build pintime
+
+package runtime
+
+import "runtime/internal/atomic"
+
+const pinTimeEnabled = true
+
+// pinTimePerM is the per-M state used to account for the time spent
+// pinned by procPin. This is embedded in the m struct.
+type pinTimePerM struct {
+	pinDepth int32 // nesting depth of procPin calls
+	pinStart int64 // nanotime of the outermost procPin
+}
+
+// pinnedTime is the total time in nanoseconds that goroutines have
+// spent pinned by procPin, updated by the outermost procUnpin.
+var pinnedTime atomic.Int64
+
+// pinTimeStart records the start of a pinned section on mp.
+//
+//go:nosplit
+func pinTimeStart(mp *m) {
+	if mp.pinDepth == 0 {
+		mp.pinStart = nanotime()
+	}
+	mp.pinDepth++
+}
+
+// pinTimeEnd records the end of a pinned section on mp.
+//
+//go:nosplit
+func pinTimeEnd(mp *m) {
+	mp.pinDepth--
+	if mp.pinDepth == 0 {
+		pinnedTime.Add(nanotime() - mp.pinStart)
+	}
+}
+
+// readPinnedTime returns the total time spent pinned in nanoseconds.
+func readPinnedTime() int64 {
+	return pinnedTime.Load()
+}
@@ -3492,6 +3492,7 @@ func goexit0(gp *g) {
 
 	casgstatus(gp, _Grunning, _Gdead)
 	gcController.addScannableStack(_p_, -int64(gp.stack.hi-gp.stack.lo))
+	addLiveStack(_p_, gp.stack.hi-gp.stack.lo, -1)
 	if isSystemGoroutine(gp, false) {
 		atomic.Xadd(&sched.ngsys, -1)
 	}
@@ -3558,9 +3559,7 @@ func goexit0(gp *g) {
 //go:nosplit
 //go:nowritebarrierrec
 func save(pc, sp uintptr) {
-	_g_ := getg()
-
-	if _g_ == _g_.m.g0 || _g_ == _g_.m.gsignal {
+	if onSystemStack() {
 		// m.g0.sched is special and must describe the context
 		// for exiting the thread. mstart1 writes to it directly.
 		// m.gsignal.sched should not be used at all.
@@ -3569,6 +3568,7 @@ func save(pc, sp uintptr) {
 		throw("save on system g not allowed")
 	}
 
+	_g_ := getg()
 	_g_.sched.pc = pc
 	_g_.sched.sp = sp
 	_g_.sched.lr = 0
@@ -4184,6 +4184,7 @@ func newproc1(fn *funcval, callergp *g, callerpc uintptr) *g {
 	}
 	casgstatus(newg, _Gdead, _Grunnable)
 	gcController.addScannableStack(_p_, int64(newg.stack.hi-newg.stack.lo))
+	addLiveStack(_p_, newg.stack.hi-newg.stack.lo, +1)
 
 	if _p_.goidcache == _p_.goidcacheend {
 		// Sched.goidgen is the last allocated id,
@@ -4795,6 +4796,9 @@ func (pp *p) destroy() {
 		raceprocdestroy(pp.raceprocctx)
 		pp.raceprocctx = 0
 	}
+	for i := range pp.liveStacks {
+		liveStacksNoP[i].Add(pp.liveStacks[i].Swap(0))
+	}
 	pp.gcAssistTime = 0
 	pp.status = _Pdead
 }
@@ -6164,15 +6168,76 @@ func procPin() int {
 	mp := _g_.m
 
 	mp.locks++
+	if pinTimeEnabled {
+		pinTimeStart(mp)
+	}
 	return int(mp.p.ptr().id)
 }
 
 //go:nosplit
 func procUnpin() {
 	_g_ := getg()
+	if pinTimeEnabled {
+		pinTimeEnd(_g_.m)
+	}
 	_g_.m.locks--
 }
 
+// procPinBounded is like procPin for a pinned section that must not
+// delay preemption for long. It sets a deadline maxNanos from now, and
+// the caller checks procPinExpired as it goes: once that reports true,
+// the caller should give up the section, unpin with procUnpinBounded so
+// that the scheduler can run, and retry. Nested bounded sections share
+// the deadline of the outermost one.
+//
+// If the goroutine has already been asked to yield, procPinBounded does
+// not pin and reports false. The caller should then retry later, for
+// example after Gosched.
+//
+//go:nosplit
+func procPinBounded(maxNanos int64) (int, bool) {
+	_g_ := getg()
+	if _g_.preempt {
+		return 0, false
+	}
+	mp := _g_.m
+	if mp.pinBoundedDepth == 0 {
+		mp.pinDeadline = nanotime() + maxNanos
+	}
+	mp.pinBoundedDepth++
+	return procPin(), true
+}
+
+// procUnpinBounded ends a section pinned by procPinBounded. Unlike
+// procUnpin, it restores a preemption request that arrived while pinned,
+// so that the goroutine yields promptly.
+//
+//go:nosplit
+func procUnpinBounded() {
+	_g_ := getg()
+	mp := _g_.m
+	mp.pinBoundedDepth--
+	if mp.pinBoundedDepth == 0 {
+		mp.pinDeadline = 0
+	}
+	procUnpin()
+	if mp.locks == 0 && _g_.preempt {
+		// Restore the preemption request in case newstack cleared it
+		// while the goroutine was pinned.
+		_g_.stackguard0 = stackPreempt
+	}
+}
+
+// procPinExpired reports whether the section pinned by procPinBounded
+// should end: its deadline has passed, or the goroutine has been asked
+// to yield since it was pinned.
+//
+//go:nosplit
+func procPinExpired() bool {
+	_g_ := getg()
+	return _g_.preempt || nanotime() >= _g_.m.pinDeadline
+}
+
 //go:linkname sync_runtime_procPin sync.runtime_procPin
 //go:nosplit
 func sync_runtime_procPin() int {
@@ -6207,7 +6272,8 @@ func sync_runtime_canSpin(i int) bool {
 	// GOMAXPROCS>1 and there is at least one other running P and local runq is empty.
 	// As opposed to runtime mutex we don't do passive spinning here,
 	// because there can be work on global runq or on other Ps.
-	if i >= active_spin || ncpu <= 1 || gomaxprocs <= int32(sched.npidle+sched.nmspinning)+1 {
+	// The limit is active_spin unless overridden by GODEBUG=syncspin=N.
+	if i >= int(debug.syncspin) || ncpu <= 1 || gomaxprocs <= int32(sched.npidle+sched.nmspinning)+1 {
 		return false
 	}
 	if p := getg().m.p.ptr(); !runqempty(p) {
@@ -6222,6 +6288,27 @@ func sync_runtime_doSpin() {
 	procyield(active_spin_cnt)
 }
 
+// spinWait backs off in a compare-and-swap retry loop that has
+// already failed iter times. The first few iterations spin on the
+// CPU, which keeps latency low when contention is brief; later ones
+// yield the OS thread and then the P, to avoid burning CPU while
+// another goroutine holds on to the contended word.
+func spinWait(iter int) {
+	switch {
+	case iter < active_spin:
+		procyield(active_spin_cnt)
+	case iter < active_spin+passive_spin:
+		osyield()
+	default:
+		Gosched()
+	}
+}
+
+//go:linkname poll_runtime_spinWait internal/poll.runtime_spinWait
+func poll_runtime_spinWait(iter int) {
+	spinWait(iter)
+}
+
 var stealOrder randomOrder
 
 // randomOrder/randomEnum are helper types for randomized work stealing.
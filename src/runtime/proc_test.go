@@ -852,6 +852,27 @@ func benchmarkWakeupParallel(b *testing.B, spin func(time.Duration)) {
 	}
 }
 
+func BenchmarkMutexContendedSpin(b *testing.B) {
+	for _, spin := range []int32{0, 4, 16, 64} {
+		b.Run(fmt.Sprintf("syncspin=%d", spin), func(b *testing.B) {
+			defer runtime.SetSyncSpin(runtime.SetSyncSpin(spin))
+			var mu sync.Mutex
+			var shared [8]uint64
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					// Hold the lock briefly, so that waiters
+					// that spin usually acquire it without parking.
+					mu.Lock()
+					for i := range shared {
+						shared[i]++
+					}
+					mu.Unlock()
+				}
+			})
+		})
+	}
+}
+
 func BenchmarkWakeupParallelSpinning(b *testing.B) {
 	benchmarkWakeupParallel(b, func(d time.Duration) {
 		end := time.Now().Add(d)
@@ -944,6 +965,39 @@ func TestStealOrder(t *testing.T) {
 	runtime.RunStealOrderTest()
 }
 
+func TestMID(t *testing.T) {
+	// Goroutines locked to their threads at the same time
+	// run on different Ms, each of which keeps its ID.
+	const n = 4
+	ids := make(chan int64)
+	release := make(chan bool)
+	for i := 0; i < n; i++ {
+		go func() {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+			id := runtime.MID()
+			ids <- id
+			<-release
+			if got := runtime.MID(); got != id {
+				t.Errorf("MID changed from %d to %d while locked to the thread", id, got)
+			}
+			ids <- id
+		}()
+	}
+	seen := make(map[int64]bool)
+	for i := 0; i < n; i++ {
+		id := <-ids
+		if id < 0 || seen[id] {
+			t.Errorf("MID = %d on a locked thread, want a distinct non-negative ID", id)
+		}
+		seen[id] = true
+	}
+	close(release)
+	for i := 0; i < n; i++ {
+		<-ids
+	}
+}
+
 func TestLockOSThreadNesting(t *testing.T) {
 	if runtime.GOARCH == "wasm" {
 		t.Skip("no threads on wasm yet")
@@ -1155,3 +1209,37 @@ func TestBigGOMAXPROCS(t *testing.T) {
 		t.Errorf("output:\n%s\nwanted:\nunknown function: NonexistentTest", output)
 	}
 }
+
+func TestProcPinBounded(t *testing.T) {
+	const bound = int64(time.Millisecond)
+	for {
+		// Yield first so that a pending preemption request does not
+		// prevent pinning.
+		runtime.Gosched()
+		pid, ok := runtime.ProcPinBounded(bound)
+		if !ok {
+			continue
+		}
+		if pid < 0 || pid >= runtime.GOMAXPROCS(0) {
+			runtime.ProcUnpinBounded()
+			t.Fatalf("ProcPinBounded returned P %d", pid)
+		}
+		break
+	}
+	start := time.Now()
+
+	// A nested section does not extend the deadline.
+	if _, ok := runtime.ProcPinBounded(int64(time.Hour)); ok {
+		for !runtime.ProcPinExpired() && time.Since(start) < time.Minute {
+		}
+		runtime.ProcUnpinBounded()
+	}
+	for !runtime.ProcPinExpired() && time.Since(start) < time.Minute {
+	}
+	elapsed := time.Since(start)
+	runtime.ProcUnpinBounded()
+
+	if elapsed >= time.Minute {
+		t.Errorf("bounded section of %v did not expire within %v", time.Duration(bound), elapsed)
+	}
+}
@@ -305,23 +305,25 @@ type dbgVar struct {
 // existing int var for that value, which may
 // already have an initial value.
 var debug struct {
-	cgocheck           int32
-	clobberfree        int32
-	efence             int32
-	gccheckmark        int32
-	gcpacertrace       int32
-	gcshrinkstackoff   int32
-	gcstoptheworld     int32
-	gctrace            int32
-	invalidptr         int32
-	madvdontneed       int32 // for Linux; issue 28466
-	scavtrace          int32
-	scheddetail        int32
-	schedtrace         int32
-	tracebackancestors int32
-	asyncpreemptoff    int32
-	harddecommit       int32
-	adaptivestackstart int32
+	cgocheck             int32
+	clobberfree          int32
+	efence               int32
+	gccheckmark          int32
+	gcpacertrace         int32
+	gcshrinkstackoff     int32
+	gcstoptheworld       int32
+	gctrace              int32
+	invalidptr           int32
+	madvdontneed         int32 // for Linux; issue 28466
+	scavtrace            int32
+	scheddetail          int32
+	schedtrace           int32
+	tracebackancestors   int32
+	asyncpreemptoff      int32
+	harddecommit         int32
+	adaptivestackstart   int32
+	syncspin             int32
+	slicegrowththreshold int32
 
 	// debug.malloc is used as a combined debug check
 	// in the malloc function and should be set
@@ -353,6 +355,8 @@ var dbgvars = []dbgVar{
 	{"inittrace", &debug.inittrace},
 	{"harddecommit", &debug.harddecommit},
 	{"adaptivestackstart", &debug.adaptivestackstart},
+	{"syncspin", &debug.syncspin},
+	{"slicegrowththreshold", &debug.slicegrowththreshold},
 }
 
 func parsedebugvars() {
@@ -360,6 +364,7 @@ func parsedebugvars() {
 	debug.cgocheck = 1
 	debug.invalidptr = 1
 	debug.adaptivestackstart = 1 // go119 - set this to 0 to turn larger initial goroutine stacks off
+	debug.syncspin = active_spin
 	if GOOS == "linux" {
 		// On Linux, MADV_FREE is faster than MADV_DONTNEED,
 		// but doesn't affect many of the statistics that
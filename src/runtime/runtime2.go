@@ -615,6 +615,12 @@ type m struct {
 
 	dlogPerM
 
+	pinTimePerM
+
+	// State of a bounded pinned section; see procPinBounded.
+	pinBoundedDepth int32 // nesting depth of procPinBounded calls
+	pinDeadline     int64 // nanotime by which the section should end
+
 	mOS
 
 	// Up to 10 locks held by this m, maintained by the lock ranking code.
@@ -764,6 +770,10 @@ type p struct {
 	// or -maxStackScanSlack is reached.
 	maxStackScanDelta int64
 
+	// liveStacks counts the stacks of live goroutines by size.
+	// See addLiveStack.
+	liveStacks [64]atomic.Int64
+
 	// gc-time statistics about current goroutines
 	// Note that this differs from maxStackScan in that this
 	// accumulates the actual stack observed to be used at GC time (hi - sp),
@@ -45,6 +45,11 @@ type semaRoot struct {
 
 var semtable semTable
 
+// mutexWaitTime is the total time in nanoseconds that goroutines have
+// spent blocked in semacquire1 on behalf of sync.Mutex and sync.RWMutex,
+// reported by the /sync/mutex/wait-total:seconds metric.
+var mutexWaitTime atomic.Int64
+
 // Prime to not correlate with any user patterns.
 const semTabSize = 251
 
@@ -140,6 +145,10 @@ func semacquire1(addr *uint32, lifo bool, profile semaProfileFlags, skipframes i
 		}
 		s.acquiretime = t0
 	}
+	var waitStart int64
+	if profile&semaMutexProfile != 0 {
+		waitStart = nanotime()
+	}
 	for {
 		lockWithRank(&root.lock, lockRankRoot)
 		// Add ourselves to nwait to disable "easy case" in semrelease.
@@ -158,6 +167,9 @@ func semacquire1(addr *uint32, lifo bool, profile semaProfileFlags, skipframes i
 			break
 		}
 	}
+	if waitStart != 0 {
+		mutexWaitTime.Add(nanotime() - waitStart)
+	}
 	if s.releasetime > 0 {
 		blockevent(s.releasetime-t0, 3+skipframes)
 	}
@@ -627,3 +639,8 @@ func notifyListCheck(sz uintptr) {
 func sync_nanotime() int64 {
 	return nanotime()
 }
+
+//go:linkname sync_sleep sync.runtime_sleep
+func sync_sleep(ns int64) {
+	timeSleep(ns)
+}
@@ -55,8 +55,13 @@ func makeslicecopy(et *_type, tolen int, fromlen int, from unsafe.Pointer) unsaf
 	var to unsafe.Pointer
 	if et.ptrdata == 0 {
 		to = mallocgc(tomem, nil, false)
-		if copymem < tomem {
-			memclrNoHeapPointers(add(to, copymem), tomem-copymem)
+		if n := tomem - copymem; n > memclrChunkBytes {
+			// Clearing a large tail can take long enough to delay
+			// preemption; the tail holds no pointers, so it is safe
+			// for the GC to observe it partially cleared.
+			memclrNoHeapPointersChunked(n, add(to, copymem))
+		} else if n > 0 {
+			memclrNoHeapPointers(add(to, copymem), n)
 		}
 	} else {
 		// Note: can't use rawmem (which avoids zeroing of memory), because then GC can scan uninitialized memory.
@@ -86,6 +91,9 @@ func makeslicecopy(et *_type, tolen int, fromlen int, from unsafe.Pointer) unsaf
 }
 
 func makeslice(et *_type, len, cap int) unsafe.Pointer {
+	if makesliceHookEnabled && makesliceHook != nil {
+		makesliceHook(et.size, len, cap)
+	}
 	mem, overflow := math.MulUintptr(et.size, uintptr(cap)) // note 每个元素的大小*cap得到需要的总空间大小
 	if overflow || mem > maxAlloc || len < 0 || len > cap {
 		// NOTE: Produce a 'len out of range' error instead of a
@@ -202,7 +210,13 @@ func growslice(et *_type, old slice, cap int) slice {
 	if cap > doublecap { // note  requirecap>doublecap，=requirecap
 		newcap = cap
 	} else { //  note requirecap<doublecap
-		const threshold = 256
+		// The threshold can be tuned with GODEBUG=slicegrowththreshold=N.
+		// growslice may run before the GODEBUG settings are parsed,
+		// while the setting is still zero.
+		threshold := 256
+		if t := debug.slicegrowththreshold; t > 0 {
+			threshold = int(t)
+		}
 		if old.cap < threshold { // note cap没到达256这个阈值，=doublecap
 			newcap = doublecap
 		} else {
@@ -310,7 +324,11 @@ func growslice(et *_type, old slice, cap int) slice {
 		p = mallocgc(capmem, nil, false)
 		// The append() that calls growslice is going to overwrite from old.len to cap (which will be the new length).
 		// Only clear the part that will not be overwritten.
-		memclrNoHeapPointers(add(p, newlenmem), capmem-newlenmem)
+		if n := capmem - newlenmem; n > memclrChunkBytes {
+			memclrNoHeapPointersChunked(n, add(p, newlenmem)) // possible preemption point, as in makeslicecopy
+		} else {
+			memclrNoHeapPointers(add(p, newlenmem), n)
+		}
 	} else {
 		// Note: can't use rawmem (which avoids zeroing of memory), because then GC can scan uninitialized memory.
 		p = mallocgc(capmem, et, true)
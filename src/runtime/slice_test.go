@@ -6,6 +6,7 @@ package runtime_test
 
 import (
 	"fmt"
+	"runtime"
 	"testing"
 )
 
@@ -499,3 +500,35 @@ func BenchmarkAppendInPlace(b *testing.B) {
 
 	})
 }
+
+var sinkSlice []int64
+
+func TestMakesliceHook(t *testing.T) {
+	if !runtime.MakesliceHookEnabled {
+		t.Skip("makeslice hook not enabled; build with -tags makeslicehook")
+	}
+	n, c := 5, 100
+	var found bool
+	runtime.SetMakesliceHook(func(elemSize uintptr, len, cap int) {
+		if elemSize == 8 && len == n && cap == c {
+			found = true
+		}
+	})
+	sinkSlice = make([]int64, n, c)
+	runtime.SetMakesliceHook(nil)
+	sinkSlice = nil
+	if !found {
+		t.Errorf("makeslice hook not called for make([]int64, %d, %d)", n, c)
+	}
+}
+
+func TestSliceGrowthThreshold(t *testing.T) {
+	grow := func() int { return cap(append(make([]int64, 512), 0)) }
+	if c := grow(); c >= 1024 {
+		t.Errorf("appending to a full slice of 512 grew it to capacity %d, want less than 1024", c)
+	}
+	defer runtime.SetSliceGrowthThreshold(runtime.SetSliceGrowthThreshold(1024))
+	if c := grow(); c != 1024 {
+		t.Errorf("with slicegrowththreshold=1024, appending to a full slice of 512 grew it to capacity %d, want 1024", c)
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !makeslicehook
+
+package runtime
+
+const makesliceHookEnabled = false
+
+// makesliceHook is always nil without the makeslicehook build tag.
+var makesliceHook func(elemSize uintptr, len, cap int)
+
+func setMakesliceHook(f func(elemSize uintptr, len, cap int)) {}
@@ -0,0 +1,24 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build makeslicehook
+
+package runtime
+
+const makesliceHookEnabled = true
+
+// makesliceHook, if non-nil, is called at the top of every makeslice
+// with the element size and the requested length and capacity.
+var makesliceHook func(elemSize uintptr, len, cap int)
+
+// setMakesliceHook installs f as the makeslice hook, or removes the
+// hook if f is nil. It is only available in runtimes built with the
+// makeslicehook build tag, for allocation analysis in tests and
+// debugging.
+//
+// f runs on the allocating goroutine for every slice made by make,
+// so it must be cheap and must not itself call make for a slice.
+func setMakesliceHook(f func(elemSize uintptr, len, cap int)) {
+	makesliceHook = f
+}
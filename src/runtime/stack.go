@@ -868,6 +868,10 @@ func copystack(gp *g, newsize uintptr) {
 	// It's also fine if we have no P, addScannableStack can deal with
 	// that case.
 	gcController.addScannableStack(getg().m.p.ptr(), int64(newsize)-int64(old.hi-old.lo))
+	// Count the new size before uncounting the old one, so that
+	// maxGoroutineStack never misses this goroutine.
+	addLiveStack(getg().m.p.ptr(), newsize, +1)
+	addLiveStack(getg().m.p.ptr(), old.hi-old.lo, -1)
 
 	// allocate new stack
 	new := stackalloc(uint32(newsize))
@@ -1482,3 +1486,43 @@ func gcComputeStartingStackSize() {
 	// Note: maxstacksize fits in 30 bits, so avg also does.
 	startingStackSize = uint32(round2(int32(avg)))
 }
+
+// The stacks of live goroutines are counted by size, so that
+// maxGoroutineStack need not walk all goroutines. Stack sizes are
+// powers of two, and index i of a count array counts the stacks of
+// 1<<i bytes. Like gcController.addScannableStack, next to which the
+// counts are updated, each P keeps its own counts in p.liveStacks so
+// that starting and exiting goroutines do not contend. A goroutine may
+// exit on another P than it started on, so only the sum over all Ps is
+// meaningful. Updates made without a P, and the counts of destroyed
+// Ps, go to liveStacksNoP. Goroutines of extra Ms running cgo
+// callbacks are not counted.
+var liveStacksNoP [64]atomic.Int64
+
+// addLiveStack adds delta to the number of live stacks of the given
+// size, in the counts of pp if it is not nil.
+func addLiveStack(pp *p, size uintptr, delta int64) {
+	i := sys.TrailingZeros64(uint64(size))
+	if pp == nil {
+		liveStacksNoP[i].Add(delta)
+		return
+	}
+	pp.liveStacks[i].Add(delta)
+}
+
+// maxGoroutineStack returns the size of the largest stack of any live
+// goroutine at the time of the call.
+func maxGoroutineStack() uintptr {
+	lock(&allpLock)
+	defer unlock(&allpLock)
+	for i := len(liveStacksNoP) - 1; i >= 0; i-- {
+		n := liveStacksNoP[i].Load()
+		for _, pp := range allp {
+			n += pp.liveStacks[i].Load()
+		}
+		if n > 0 {
+			return 1 << i
+		}
+	}
+	return 0
+}
@@ -937,3 +937,13 @@ func tracebackNotExcluded() {
 	var x *int
 	*x = 0
 }
+
+func TestOnSystemStack(t *testing.T) {
+	user, system := OnSystemStack()
+	if user {
+		t.Errorf("onSystemStack() = true on a user goroutine")
+	}
+	if !system {
+		t.Errorf("onSystemStack() = false within systemstack")
+	}
+}
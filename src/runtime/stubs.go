@@ -463,6 +463,16 @@ func call1073741824(typ, fn, stackArgs unsafe.Pointer, stackArgsSize, stackRetOf
 
 func systemstack_switch()
 
+// onSystemStack reports whether the current goroutine is running on the
+// system stack, that is, whether it is the g0 or gsignal of its M, as
+// it is within systemstack and in signal handlers.
+//
+//go:nosplit
+func onSystemStack() bool {
+	gp := getg()
+	return gp == gp.m.g0 || gp == gp.m.gsignal
+}
+
 // alignUp rounds n up to a multiple of a. a must be a power of 2.
 // tag 算法
 // 使用 &^ 运算符实现了将数字 n 向上舍入到指定对齐大小 a 的倍数的功能。
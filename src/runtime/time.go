@@ -1054,6 +1054,30 @@ func timeSleepUntil() int64 {
 	return next
 }
 
+// activeTimers returns the number of timers in the P's heaps that are
+// still armed, not counting deleted timers waiting to be removed.
+// This is only called by runtime/metrics.
+func activeTimers() uint64 {
+	var n int64
+
+	// Prevent allp slice changes. This is like timeSleepUntil.
+	lock(&allpLock)
+	for _, pp := range allp {
+		if pp == nil {
+			continue
+		}
+		n += int64(atomic.Load(&pp.numTimers)) - int64(atomic.Load(&pp.deletedTimers))
+	}
+	unlock(&allpLock)
+
+	// The two counts of a P are not read at the same moment, so
+	// a timer being deleted can briefly make the difference negative.
+	if n < 0 {
+		n = 0
+	}
+	return uint64(n)
+}
+
 // Heap maintenance algorithms.
 // These algorithms check for slice index errors manually.
 // Slice index error can happen if the program is using racy
@@ -189,6 +189,55 @@ func (v *Value) CompareAndSwap(old, new any) (swapped bool) {
 	}
 }
 
+// A TypedValue provides an atomic load and store of a value of type T.
+// Unlike a Value, it can hold values of an interface type T with
+// different concrete types, as well as nil values. The zero value for
+// a TypedValue holds no value.
+//
+// A TypedValue is implemented with a Value. Storing a value of a
+// pointer type T, like storing a pointer in a Value, does not allocate.
+//
+// A TypedValue must not be copied after first use.
+type TypedValue[T any] struct {
+	_ noCopy
+	v Value // of typedValueBox[T]
+}
+
+// A typedValueBox holds the value of a TypedValue[T] in its Value, so
+// that the Value's concrete type is always the same. For a pointer type
+// T, the box is itself pointer-shaped and storing it in an interface
+// does not allocate.
+type typedValueBox[T any] struct {
+	v T
+}
+
+// Load atomically loads and returns the value stored in x, and reports
+// whether a value has been stored. If none has been stored, Load
+// returns the zero value of T and false.
+func (x *TypedValue[T]) Load() (val T, ok bool) {
+	b, ok := x.v.Load().(typedValueBox[T])
+	return b.v, ok
+}
+
+// Store atomically stores val into x.
+func (x *TypedValue[T]) Store(val T) { x.v.Store(typedValueBox[T]{val}) }
+
+// Swap atomically stores new into x and returns the previous value,
+// reporting whether there was one. If there was not, Swap returns the
+// zero value of T and false.
+func (x *TypedValue[T]) Swap(new T) (old T, loaded bool) {
+	b, loaded := x.v.Swap(typedValueBox[T]{new}).(typedValueBox[T])
+	return b.v, loaded
+}
+
+// CompareAndSwap executes the compare-and-swap operation for x. The
+// values are compared with ==, so CompareAndSwap panics if they are not
+// comparable, for example because they are interfaces holding slices.
+// CompareAndSwap reports false if no value has been stored in x.
+func (x *TypedValue[T]) CompareAndSwap(old, new T) (swapped bool) {
+	return x.v.CompareAndSwap(typedValueBox[T]{old}, typedValueBox[T]{new})
+}
+
 // Disable/enable preemption, implemented in runtime.
 func runtime_procPin()
 func runtime_procUnpin()
@@ -5,6 +5,8 @@
 package atomic_test
 
 import (
+	"errors"
+	"io"
 	"math/rand"
 	"runtime"
 	"strconv"
@@ -272,3 +274,73 @@ func TestValueCompareAndSwapConcurrent(t *testing.T) {
 		t.Errorf("did not get to %v, stopped at %v", m*n, stop)
 	}
 }
+
+func TestTypedValue(t *testing.T) {
+	var v TypedValue[error]
+	if x, ok := v.Load(); x != nil || ok {
+		t.Fatalf("initial TypedValue = %v, %v, want nil, false", x, ok)
+	}
+	if v.CompareAndSwap(nil, io.EOF) {
+		t.Fatalf("CompareAndSwap on empty TypedValue succeeded")
+	}
+	// Values of different concrete types, and nil, can be stored.
+	v.Store(io.EOF)
+	if x, ok := v.Load(); x != io.EOF || !ok {
+		t.Fatalf("Load = %v, %v, want %v, true", x, ok, io.EOF)
+	}
+	if old, loaded := v.Swap(nil); old != io.EOF || !loaded {
+		t.Fatalf("Swap = %v, %v, want %v, true", old, loaded, io.EOF)
+	}
+	if x, ok := v.Load(); x != nil || !ok {
+		t.Fatalf("Load after storing nil = %v, %v, want nil, true", x, ok)
+	}
+	if !v.CompareAndSwap(nil, errors.New("x")) {
+		t.Fatalf("CompareAndSwap(nil, ...) = false, want true")
+	}
+	if v.CompareAndSwap(nil, io.EOF) {
+		t.Fatalf("CompareAndSwap with wrong old value succeeded")
+	}
+
+	var s TypedValue[string]
+	if old, loaded := s.Swap("a"); old != "" || loaded {
+		t.Fatalf("Swap on empty TypedValue = %q, %v, want \"\", false", old, loaded)
+	}
+	if !s.CompareAndSwap("a", "b") {
+		t.Fatalf("CompareAndSwap(\"a\", \"b\") = false, want true")
+	}
+	if x, _ := s.Load(); x != "b" {
+		t.Fatalf("Load = %q, want %q", x, "b")
+	}
+}
+
+func TestTypedValuePointerAllocs(t *testing.T) {
+	var v TypedValue[*int]
+	p := new(int)
+	allocs := testing.AllocsPerRun(100, func() {
+		v.Store(p)
+		v.Swap(p)
+		v.CompareAndSwap(p, p)
+		v.Load()
+	})
+	if allocs != 0 {
+		t.Errorf("TypedValue[*int] operations allocated %v times, want 0", allocs)
+	}
+}
+
+func BenchmarkTypedValueStore(b *testing.B) {
+	b.Run("pointer", func(b *testing.B) {
+		var v TypedValue[*int]
+		p := new(int)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			v.Store(p)
+		}
+	})
+	b.Run("struct", func(b *testing.B) {
+		var v TypedValue[[2]int]
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			v.Store([2]int{i, i})
+		}
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+// A BoundedGroup runs functions in their own goroutines, with at most
+// a fixed number of them running at any one time, and waits for them
+// to finish. It combines a WaitGroup with a counting semaphore.
+//
+// A BoundedGroup must be created with NewBoundedGroup and must not be
+// copied after first use.
+type BoundedGroup struct {
+	wg  WaitGroup
+	sem chan struct{}
+}
+
+// NewBoundedGroup returns a BoundedGroup that runs at most limit
+// functions concurrently. It panics if limit is not positive.
+func NewBoundedGroup(limit int) *BoundedGroup {
+	if limit <= 0 {
+		panic("sync: non-positive BoundedGroup limit")
+	}
+	return &BoundedGroup{sem: make(chan struct{}, limit)}
+}
+
+// Go calls f in a new goroutine. If limit functions started by Go are
+// already running, Go blocks until one of them returns.
+//
+// As with WaitGroup.Add, a call to Go that starts a new batch of
+// functions must happen before the corresponding call to Wait.
+func (g *BoundedGroup) Go(f func()) {
+	g.sem <- struct{}{}
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			<-g.sem
+			g.wg.Done()
+		}()
+		f()
+	}()
+}
+
+// Wait blocks until all functions started by Go have returned.
+func (g *BoundedGroup) Wait() {
+	g.wg.Wait()
+}
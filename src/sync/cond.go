@@ -71,6 +71,51 @@ func (c *Cond) Wait() {
 	c.L.Lock()
 }
 
+// WaitContext is like Wait but also returns when ctx is done.
+// Like Wait, WaitContext always locks c.L before returning.
+//
+// WaitContext returns nil if it was awoken by Signal or Broadcast, and
+// ctx.Err() if ctx was done first, including when ctx is already done
+// on entry. In the latter case c.L is not released at all.
+//
+// To unblock the calling goroutine when ctx is done, WaitContext
+// broadcasts on c, so other goroutines waiting on c may also be awoken
+// as if Broadcast had been called. Callers of Wait should already
+// recheck the condition in a loop, so this is usually harmless.
+func (c *Cond) WaitContext(ctx interface {
+	Done() <-chan struct{}
+	Err() error
+}) error {
+	done := ctx.Done()
+	if done == nil {
+		c.Wait()
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.checker.check()
+	t := runtime_notifyListAdd(&c.notify)
+	c.L.Unlock()
+	var canceled atomic.Bool
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			canceled.Store(true)
+			runtime_notifyListNotifyAll(&c.notify)
+		case <-stop:
+		}
+	}()
+	runtime_notifyListWait(&c.notify, t)
+	close(stop)
+	c.L.Lock()
+	if canceled.Load() {
+		return ctx.Err()
+	}
+	return nil
+}
+
 // Signal wakes one goroutine waiting on c, if there is any.
 //
 // It is allowed but not required for the caller to hold c.L
@@ -5,6 +5,7 @@
 package sync_test
 
 import (
+	"context"
 	"reflect"
 	"runtime"
 	. "sync"
@@ -243,6 +244,52 @@ func TestCondSignalStealing(t *testing.T) {
 	}
 }
 
+func TestCondWaitContext(t *testing.T) {
+	var m Mutex
+	c := NewCond(&m)
+
+	// Signal wakes WaitContext with a nil error.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	running := make(chan bool)
+	errc := make(chan error)
+	go func() {
+		m.Lock()
+		running <- true
+		err := c.WaitContext(ctx)
+		m.Unlock()
+		errc <- err
+	}()
+	<-running
+	m.Lock()
+	c.Signal()
+	m.Unlock()
+	if err := <-errc; err != nil {
+		t.Fatalf("WaitContext after Signal = %v, want nil", err)
+	}
+
+	// Canceling the context wakes WaitContext with the context's error.
+	go func() {
+		m.Lock()
+		running <- true
+		err := c.WaitContext(ctx)
+		m.Unlock()
+		errc <- err
+	}()
+	<-running
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Fatalf("WaitContext after cancel = %v, want %v", err, context.Canceled)
+	}
+
+	// An already-canceled context returns immediately with c.L held.
+	m.Lock()
+	if err := c.WaitContext(ctx); err != context.Canceled {
+		t.Fatalf("WaitContext with canceled context = %v, want %v", err, context.Canceled)
+	}
+	m.Unlock()
+}
+
 func TestCondCopy(t *testing.T) {
 	defer func() {
 		err := recover()
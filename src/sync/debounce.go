@@ -0,0 +1,75 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+// A Debouncer delays calling a function until a quiet period has passed
+// since it was last triggered, collapsing bursts of triggers into one
+// call.
+//
+// A Debouncer must be created with NewDebouncer and must not be copied
+// after first use.
+type Debouncer struct {
+	mu       Mutex
+	delay    int64  // quiet period in nanoseconds
+	deadline int64  // runtime_nanotime at which f is due
+	f        func() // function to call at deadline; nil if none pending
+	waiting  bool   // whether a goroutine is waiting for deadline
+}
+
+// NewDebouncer returns a Debouncer with a quiet period of d.
+func NewDebouncer(d interface{ Nanoseconds() int64 }) *Debouncer {
+	return &Debouncer{delay: d.Nanoseconds()}
+}
+
+// Trigger arranges for f to be called in its own goroutine once the
+// quiet period has passed with no further calls to Trigger or Stop.
+// Each call replaces any pending function and restarts the period.
+func (d *Debouncer) Trigger(f func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.f = f
+	d.deadline = runtime_nanotime() + d.delay
+	if !d.waiting {
+		d.waiting = true
+		go d.wait()
+	}
+}
+
+// Stop cancels the pending call, if any. It reports whether it
+// prevented a call; it returns false if no call was pending or the
+// pending function has already started. Stop does not wait for a
+// started function to return. The Debouncer may be triggered again
+// after Stop.
+func (d *Debouncer) Stop() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pending := d.f != nil
+	d.f = nil
+	return pending
+}
+
+// wait sleeps until the pending function is due, following the deadline
+// as Trigger moves it, and then calls the function.
+func (d *Debouncer) wait() {
+	for {
+		d.mu.Lock()
+		f := d.f
+		if f == nil {
+			d.waiting = false
+			d.mu.Unlock()
+			return
+		}
+		left := d.deadline - runtime_nanotime()
+		if left <= 0 {
+			d.f = nil
+			d.waiting = false
+			d.mu.Unlock()
+			f()
+			return
+		}
+		d.mu.Unlock()
+		runtime_sleep(left)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync_test
+
+import (
+	. "sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebouncer(t *testing.T) {
+	const quiet = 20 * time.Millisecond
+	d := NewDebouncer(quiet)
+	if d.Stop() {
+		t.Errorf("Stop with nothing pending = true, want false")
+	}
+
+	// A burst of triggers results in a single call of the last function.
+	var calls atomic.Int32
+	done := make(chan int, 10)
+	for i := 0; i < 5; i++ {
+		i := i
+		d.Trigger(func() {
+			calls.Add(1)
+			done <- i
+		})
+	}
+	if got := <-done; got != 4 {
+		t.Errorf("debounced call ran function %d, want 4", got)
+	}
+	time.Sleep(2 * quiet)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("burst of triggers made %d calls, want 1", n)
+	}
+
+	// Stop prevents a pending call, and the Debouncer can be reused.
+	d.Trigger(func() { done <- -1 })
+	if !d.Stop() {
+		t.Errorf("Stop with a call pending = false, want true")
+	}
+	d.Trigger(func() { done <- 5 })
+	if got := <-done; got != 5 {
+		t.Errorf("call after Stop and Trigger ran function %d, want 5", got)
+	}
+	if d.Stop() {
+		t.Errorf("Stop after the call ran = true, want false")
+	}
+	select {
+	case got := <-done:
+		t.Errorf("unexpected call of function %d", got)
+	case <-time.After(2 * quiet):
+	}
+}
@@ -4,6 +4,11 @@
 
 package sync
 
+import (
+	"std/internal/cpu"
+	"unsafe"
+)
+
 // Export for testing.
 var Runtime_Semacquire = runtime_Semacquire
 var Runtime_Semrelease = runtime_Semrelease
@@ -55,3 +60,16 @@ func (c *poolChain) PopHead() (any, bool) {
 func (c *poolChain) PopTail() (any, bool) {
 	return c.popTail()
 }
+
+// PoolLocalSize is the size of each per-P shard of a Pool.
+const PoolLocalSize = unsafe.Sizeof(poolLocal{})
+
+// CacheLinePadSize is the cache line size that poolLocal is padded to.
+const CacheLinePadSize = cpu.CacheLinePadSize
+
+// KeyedMutexLen returns the number of keys m has a lock for.
+func KeyedMutexLen[K comparable](m *KeyedMutex[K]) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.locks)
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+// A KeyedMutex is a set of mutual exclusion locks, one per key, for
+// serializing the work done on each of many entities, such as users or
+// cache entries, while letting work on different entities proceed in
+// parallel.
+//
+// The lock for a key exists only while a goroutine holds it or is
+// waiting for it, so a KeyedMutex does not grow with the number of keys
+// ever locked.
+//
+// The zero KeyedMutex is ready for use, with all keys unlocked.
+// A KeyedMutex must not be copied after first use.
+type KeyedMutex[K comparable] struct {
+	mu    Mutex
+	locks map[K]*keyedLock
+}
+
+// A keyedLock is the lock for one key of a KeyedMutex.
+type keyedLock struct {
+	mu   Mutex
+	refs int // goroutines holding or waiting for mu; guarded by KeyedMutex.mu
+}
+
+// Lock locks key. If the lock for key is already in use, the calling
+// goroutine blocks until it is available.
+func (m *KeyedMutex[K]) Lock(key K) {
+	m.mu.Lock()
+	l := m.locks[key]
+	if l == nil {
+		if m.locks == nil {
+			m.locks = make(map[K]*keyedLock)
+		}
+		l = new(keyedLock)
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.mu.Lock()
+}
+
+// Unlock unlocks key.
+// It is a run-time error if key is not locked on entry to Unlock.
+//
+// As with Mutex, a locked key is not associated with a particular
+// goroutine: one goroutine may lock a key and arrange for another to
+// unlock it.
+func (m *KeyedMutex[K]) Unlock(key K) {
+	m.mu.Lock()
+	l := m.locks[key]
+	if l == nil {
+		m.mu.Unlock()
+		fatal("sync: unlock of unlocked KeyedMutex key")
+	}
+	if l.refs--; l.refs == 0 {
+		delete(m.locks, key)
+	}
+	m.mu.Unlock()
+
+	l.mu.Unlock()
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync_test
+
+import (
+	. "sync"
+	"testing"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	var m KeyedMutex[string]
+	const goroutines, iters = 8, 1000
+	counts := map[string]*int{"a": new(int), "b": new(int)}
+	var wg WaitGroup
+	for i := 0; i < goroutines; i++ {
+		key := "a"
+		if i%2 == 1 {
+			key = "b"
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iters; j++ {
+				m.Lock(key)
+				*counts[key]++
+				m.Unlock(key)
+			}
+		}()
+	}
+	wg.Wait()
+	for key, n := range counts {
+		if *n != goroutines/2*iters {
+			t.Errorf("count for %q = %d, want %d", key, *n, goroutines/2*iters)
+		}
+	}
+	if n := KeyedMutexLen(&m); n != 0 {
+		t.Errorf("KeyedMutex holds %d locks after all keys were unlocked, want 0", n)
+	}
+}
+
+func TestKeyedMutexIndependentKeys(t *testing.T) {
+	var m KeyedMutex[int]
+	m.Lock(1)
+	done := make(chan bool)
+	go func() {
+		// Locking another key does not block.
+		m.Lock(2)
+		m.Unlock(2)
+		done <- true
+	}()
+	<-done
+
+	locked := make(chan bool)
+	go func() {
+		m.Lock(1)
+		locked <- true
+		m.Unlock(1)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("Lock of a locked key did not block")
+	default:
+	}
+	if n := KeyedMutexLen(&m); n != 1 {
+		t.Errorf("KeyedMutex holds %d locks with one key locked, want 1", n)
+	}
+	m.Unlock(1)
+	<-locked
+}
@@ -8,6 +8,11 @@
 // better done via channels and communication.
 //
 // Values containing the types defined in this package should not be copied.
+//
+// Packages context and time depend on package sync, so the few functions
+// here that wait for a context or for a duration take small interfaces
+// instead: a value with Done and Err methods, such as a context.Context,
+// or a value with a Nanoseconds method, such as a time.Duration.
 package sync
 
 import (
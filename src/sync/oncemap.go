@@ -0,0 +1,41 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+// A OnceMap lazily initializes one value per key, such as a compiled
+// regular expression per pattern, and caches it for later use.
+//
+// The zero OnceMap is ready for use.
+// A OnceMap must not be copied after first use.
+type OnceMap[K comparable, V any] struct {
+	m Map // of K to *onceValue[V]
+}
+
+// A onceValue is the value for one key of a OnceMap.
+type onceValue[V any] struct {
+	once Once
+	v    V
+}
+
+// Do returns the value for key, calling f to compute it if Do has not
+// been called for key before. For each key, f is called exactly once,
+// even if Do is called for the same key by many goroutines at the same
+// time; the others wait for the first call of f to return and then all
+// return its result. Calls of Do for different keys do not wait for
+// each other.
+//
+// As with Once, if f panics, Do considers it to have returned; later
+// calls of Do for key return the zero value of V without calling f.
+// Since no call of Do for key returns until f returns, f must not call
+// Do for the same key, or it will deadlock.
+func (m *OnceMap[K, V]) Do(key K, f func() V) V {
+	e, ok := m.m.Load(key)
+	if !ok {
+		e, _ = m.m.LoadOrStore(key, new(onceValue[V]))
+	}
+	ov := e.(*onceValue[V])
+	ov.once.Do(func() { ov.v = f() })
+	return ov.v
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync_test
+
+import (
+	. "sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOnceMap(t *testing.T) {
+	var m OnceMap[string, int]
+	var calls [2]atomic.Int32
+	const goroutines = 16
+	var wg WaitGroup
+	for i := 0; i < goroutines; i++ {
+		key, want := "a", 1
+		if i%2 == 1 {
+			key, want = "b", 2
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got := m.Do(key, func() int {
+				calls[want-1].Add(1)
+				return want
+			})
+			if got != want {
+				t.Errorf("Do(%q) = %d, want %d", key, got, want)
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range calls {
+		if n := calls[i].Load(); n != 1 {
+			t.Errorf("f for key %d called %d times, want 1", i, n)
+		}
+	}
+
+	if got := m.Do("a", func() int { panic("f called again") }); got != 1 {
+		t.Errorf("Do(%q) after initialization = %d, want 1", "a", got)
+	}
+}
+
+func TestOnceMapPanic(t *testing.T) {
+	var m OnceMap[int, string]
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("Do did not panic")
+			}
+		}()
+		m.Do(1, func() string { panic("failed") })
+	}()
+	if got := m.Do(1, func() string { return "again" }); got != "" {
+		t.Errorf("Do after a panic = %q, want zero value", got)
+	}
+	if got := m.Do(2, func() string { return "two" }); got != "two" {
+		t.Errorf("Do(2) = %q, want %q", got, "two")
+	}
+}
@@ -6,6 +6,7 @@ package sync
 
 import (
 	"runtime"
+	"std/internal/cpu"
 	"std/internal/race"
 	"sync/atomic"
 	"unsafe"
@@ -55,10 +56,117 @@ type Pool struct {
 	victim     unsafe.Pointer // local from previous cycle
 	victimSize uintptr        // size of victims array
 
+	olderVictims []poolVictim // victims from cycles before the previous one, youngest first
+	nextAging    *Pool        // next pool in agingPools
+	aging        bool         // whether the pool is in agingPools
+
+	poolStealTotals // counts for StealStats in pooldebug builds
+
 	// New optionally specifies a function to generate
 	// a value when Get would otherwise return nil.
 	// It may not be changed concurrently with calls to Get.
 	New func() any
+
+	// Finalizer optionally specifies a function to run on items that
+	// are dropped by the Pool and then become unreachable, for example
+	// to release external resources held by pooled objects.
+	//
+	// When Finalizer is set, Put installs it as the item's finalizer
+	// with runtime.SetFinalizer, and Get removes it again before
+	// returning a pooled item, so it only runs for items that the Pool
+	// has discarded. Consequently every item passed to Put must be
+	// acceptable to runtime.SetFinalizer, and Put and Get become more
+	// expensive. Put panics if it is not, for example because the item
+	// is not a pointer or already has a finalizer.
+	//
+	// As with any finalizer, Finalizer runs at most once per item, in
+	// its own goroutine, some time after the item becomes unreachable.
+	// If Finalizer stores the item somewhere, the item is resurrected
+	// but is not returned to the Pool, and if it is later Put again its
+	// finalizer is installed again.
+	//
+	// It may not be changed concurrently with calls to Put or Get.
+	Finalizer func(x any)
+
+	// Generations optionally specifies the number of garbage
+	// collection cycles that pooled items survive before the Pool
+	// drops them. A value of 0 or 1 selects the default behavior, in
+	// which items put in the Pool are moved to a victim cache by the
+	// next collection and dropped by the one after that.
+	//
+	// Larger values keep items reachable, and so keep their memory in
+	// use, for up to Generations collections after they were last Put,
+	// which helps workloads that reuse objects only in occasional
+	// bursts at the cost of a larger steady-state heap.
+	//
+	// It may not be changed after the first call to Put or Get.
+	Generations int
+
+	// SizeOf optionally specifies a function that returns the
+	// approximate size in bytes of the memory retained by a pooled
+	// item. Pools that set it contribute to the
+	// /sync/pool/retained-bytes:bytes metric of runtime/metrics; all
+	// pools contribute to /sync/pool/retained-objects:objects.
+	//
+	// SizeOf is called once by Put and once by Get for each pooled
+	// item, so it must return the same value for an item that has not
+	// been modified in between, and it should be cheap.
+	// It may not be changed concurrently with calls to Put or Get.
+	SizeOf func(x any) uintptr
+
+	// Policy specifies the order in which Get takes items from the
+	// Pool. The zero value, PoolLIFO, prefers the most recently Put
+	// items, which are most likely to still be in cache.
+	//
+	// It may not be changed after the first call to Put or Get.
+	Policy PoolPolicy
+
+	// MaxActive optionally limits the number of items handed out by
+	// GetWait that have not yet been passed back to Put, turning the
+	// Pool into a pool of a limited resource, such as connections.
+	// When the limit is reached, GetWait blocks until an item is Put.
+	// A value of 0 means no limit.
+	//
+	// When MaxActive is set, items must be taken with GetWait rather
+	// than Get, and each item must be passed to Put exactly once.
+	// Put does not know how an item was taken, so putting back an item
+	// taken with a plain Get still counts as returning one handed out
+	// by GetWait: it decrements the number of active items, or passes
+	// the item to a waiting GetWait call.
+	// It may not be changed after the first call to Put or GetWait.
+	MaxActive int
+
+	limit poolLimit
+}
+
+// poolLimit is the state of a Pool with MaxActive set.
+type poolLimit struct {
+	mu      Mutex
+	active  int        // items handed out by GetWait and not yet Put
+	waiters []chan any // FIFO queue of blocked GetWait calls
+}
+
+// A PoolPolicy specifies the order in which a Pool's Get method takes
+// items from the Pool.
+type PoolPolicy int
+
+const (
+	// PoolLIFO makes Get prefer the items Put most recently.
+	PoolLIFO PoolPolicy = iota
+
+	// PoolFIFO makes Get prefer the items Put longest ago, so that
+	// items are cycled evenly, for example to keep pooled connections
+	// from sitting idle until they time out. Items that survived a
+	// garbage collection come first; otherwise the order is only
+	// approximate, because each P keeps its own items. Put and Get are
+	// somewhat slower than with PoolLIFO.
+	PoolFIFO
+)
+
+// poolVictim is a victim cache from an earlier garbage collection cycle.
+type poolVictim struct {
+	local unsafe.Pointer // actual type is [P]poolLocal
+	size  uintptr        // size of the local array
 }
 
 // Local per-P Pool appendix.
@@ -67,6 +175,15 @@ type poolLocalInternal struct {
 	// 取的时候也是优先从private取，取不到再走shared链表
 	private any       // Can be used only by the respective P.
 	shared  poolChain // Local P can pushHead/popHead; any P can popTail.
+
+	poolStealCounters // counts for Pool.StealStats in pooldebug builds
+
+	// objects and bytes count the items stored in private and shared
+	// and their sizes as reported by Pool.SizeOf. Put only updates the
+	// counters of its own P, which are on its own cache lines, so the
+	// atomic adds do not contend.
+	objects atomic.Int64
+	bytes   atomic.Int64
 }
 
 // 这个结构体是在Go语言标准库中的sync包中定义的，它实现了一个goroutine池。
@@ -82,9 +199,9 @@ type poolLocalInternal struct {
 type poolLocal struct {
 	poolLocalInternal
 
-	// Prevents false sharing on widespread platforms with
-	// 128 mod (cache line size) = 0 .
-	pad [128 - unsafe.Sizeof(poolLocalInternal{})%128]byte
+	// Prevents false sharing by rounding the size of poolLocal up to
+	// a multiple of the cache line size of the target architecture.
+	pad [cpu.CacheLinePadSize - unsafe.Sizeof(poolLocalInternal{})%cpu.CacheLinePadSize]byte
 }
 
 // 在现代CPU中，缓存是一种重要的性能优化技术。缓存通常以线或块的形式组织，每个线或块包含许多相邻的内存位置。
@@ -148,6 +265,19 @@ func (p *Pool) Put(x any) {
 	if x == nil {
 		return
 	}
+	if p.MaxActive > 0 && p.handOff(x) {
+		return
+	}
+	if p.Finalizer != nil {
+		if msg := runtime_checkFinalizer(x); msg != "" {
+			panic("sync: Pool.Put with Finalizer set: " + msg)
+		}
+		runtime.SetFinalizer(x, p.Finalizer)
+	}
+	var size int64
+	if p.SizeOf != nil {
+		size = int64(p.SizeOf(x))
+	}
 	if race.Enabled {
 		if fastrandn(4) == 0 {
 			// Randomly drop x on floor.
@@ -157,11 +287,17 @@ func (p *Pool) Put(x any) {
 		race.Disable()
 	}
 	l, _ := p.pin()
-	if l.private == nil {
+	if l.private == nil && p.Policy != PoolFIFO {
+		// With PoolFIFO, items all go in shared so that Get
+		// can take the oldest from its tail.
 		l.private = x
 	} else {
 		l.shared.pushHead(x)
 	}
+	l.objects.Add(1)
+	if size != 0 {
+		l.bytes.Add(size)
+	}
 	runtime_procUnpin()
 	if race.Enabled {
 		race.Enable()
@@ -176,7 +312,119 @@ func (p *Pool) Put(x any) {
 //
 // If Get would otherwise return nil and p.New is non-nil, Get returns
 // the result of calling p.New.
+//
+// If p.Finalizer is non-nil, Get clears the finalizer of the item it
+// takes from the Pool.
 func (p *Pool) Get() any {
+	x := p.getPooled()
+	if x == nil && p.New != nil {
+		x = p.New()
+	}
+	return x
+}
+
+// GetFresh is like Get but also reports whether the returned item was
+// created by calling p.New rather than taken from the Pool. Callers can
+// use it to skip resetting a newly created item, or to set up only new
+// items. If GetFresh returns nil, fresh is false.
+func (p *Pool) GetFresh() (x any, fresh bool) {
+	x = p.getPooled()
+	if x == nil && p.New != nil {
+		return p.New(), true
+	}
+	return x, false
+}
+
+// GetWait is like Get, but if p.MaxActive is set and that many items
+// handed out by GetWait have not been Put back yet, it blocks until
+// another goroutine calls Put, and returns the item Put, or until ctx
+// is done, and returns nil and ctx.Err(). If ctx is already done,
+// GetWait may still succeed without blocking. Waiting calls are served
+// in FIFO order. If p.MaxActive is not set, GetWait is the same as Get.
+//
+// As with Get, GetWait returns nil and a nil error if the Pool is empty
+// and p.New is nil.
+func (p *Pool) GetWait(ctx interface {
+	Done() <-chan struct{}
+	Err() error
+}) (any, error) {
+	if p.MaxActive <= 0 {
+		return p.Get(), nil
+	}
+	p.limit.mu.Lock()
+	if p.limit.active < p.MaxActive {
+		p.limit.active++
+		p.limit.mu.Unlock()
+		return p.getActive(), nil
+	}
+	ready := make(chan any, 1)
+	p.limit.waiters = append(p.limit.waiters, ready)
+	p.limit.mu.Unlock()
+
+	select {
+	case x := <-ready:
+		if x == nil {
+			// We were handed a slot but no item.
+			x = p.getActive()
+		}
+		return x, nil
+	case <-ctx.Done():
+		err := ctx.Err()
+		p.limit.mu.Lock()
+		for i, w := range p.limit.waiters {
+			if w == ready {
+				p.limit.waiters = append(p.limit.waiters[:i], p.limit.waiters[i+1:]...)
+				p.limit.mu.Unlock()
+				return nil, err
+			}
+		}
+		p.limit.mu.Unlock()
+		// Put handed us an item after we were canceled.
+		// Rather than handing it to someone else, just
+		// pretend we didn't notice the cancellation.
+		x := <-ready
+		if x == nil {
+			x = p.getActive()
+		}
+		return x, nil
+	}
+}
+
+// getActive gets an item for a GetWait call that holds one of the
+// MaxActive slots. If there is no item, nothing will be Put back, so
+// it passes the slot on to the longest waiting GetWait call or frees it.
+func (p *Pool) getActive() any {
+	x := p.Get()
+	if x == nil {
+		p.handOff(nil)
+	}
+	return x
+}
+
+// handOff passes x to the longest waiting GetWait call and reports
+// whether there was one. If there was none, it records that an item
+// handed out by GetWait is back. A nil x passes on just the slot, and
+// the waiting call gets an item itself.
+func (p *Pool) handOff(x any) bool {
+	p.limit.mu.Lock()
+	if len(p.limit.waiters) == 0 {
+		if p.limit.active > 0 {
+			p.limit.active--
+		}
+		p.limit.mu.Unlock()
+		return false
+	}
+	w := p.limit.waiters[0]
+	p.limit.waiters[0] = nil // allow the channel to be collected
+	p.limit.waiters = p.limit.waiters[1:]
+	p.limit.mu.Unlock()
+	w <- x
+	return true
+}
+
+// getPooled removes an item from the Pool and returns it,
+// or returns nil if the Pool is empty. It does not call p.New.
+func (p *Pool) getPooled() any {
 	// 这段代码是用来禁用 Go 语言程序中的竞争检测工具（Race Detector）的。
 	//在 Go 语言标准库中，有一个叫做 race 的包，其中包含了一些用于对应用程序进行竞争检测的函数和变量。
 	//这段代码中的 if race.Enabled 检查当前是否启用了竞争检测，如果启用了，则调用 race.Disable() 函数来禁用竞争检测。
@@ -187,6 +435,7 @@ func (p *Pool) Get() any {
 	}
 	// 调用 pin() 方法获取当前协程关联的本地页（local shard）和池的 ID 号。todo
 	l, pid := p.pin()
+	from := l      // the poolLocal x is taken from
 	x := l.private // 从本地页中取出私有资源，并置空以便下次使用
 	l.private = nil
 	if x == nil {
@@ -195,12 +444,27 @@ func (p *Pool) Get() any {
 		//这里的目的是利用局部性原理（temporal locality）提高资源的重用率。
 		// Try to pop the head of the local shard. We prefer
 		// the head over the tail for temporal locality of
-		// reuse.
-		x, _ = l.shared.popHead()
+		// reuse, unless the Pool asks for the oldest item:
+		// then try the victim caches, which hold items older
+		// than any in the primary caches, and the tail.
+		if p.Policy == PoolFIFO {
+			x, from = p.getVictims(pid, true)
+			if x != nil {
+				l.countVictimHit()
+			} else {
+				x, _ = l.shared.popTail()
+				from = l
+			}
+		} else {
+			x, _ = l.shared.popHead()
+		}
 		if x == nil {
-			x = p.getSlow(pid)
+			x, from = p.getSlow(pid)
 		}
 	}
+	if x != nil {
+		from.objects.Add(-1)
+	}
 	runtime_procUnpin() // 将当前协程从本地页上解除关联
 	if race.Enabled {
 		race.Enable()
@@ -208,49 +472,106 @@ func (p *Pool) Get() any {
 			race.Acquire(poolRaceAddr(x)) // 使用 poolRaceAddr(x) 函数获取其内存地址，并调用 Acquire 函数告诉竞争检测工具这段代码正在访问这个资源
 		}
 	}
-	if x == nil && p.New != nil {
-		x = p.New()
+	if x != nil && p.Finalizer != nil {
+		runtime.SetFinalizer(x, nil)
+	}
+	if x != nil && p.SizeOf != nil {
+		// Call SizeOf unpinned. If from has since moved to a victim
+		// cache, it is still the right place to account for x, and if
+		// it has been dropped, the update is harmless.
+		from.bytes.Add(-int64(p.SizeOf(x)))
 	}
 	return x
 }
 
-func (p *Pool) getSlow(pid int) any {
+// getSlow takes an item from another P's cache or from the victim
+// caches, and returns it and the poolLocal it was taken from.
+//
+// With PoolFIFO, Get has already tried the victim caches and the tail
+// of pid's own shard, so getSlow only steals from the other Ps.
+func (p *Pool) getSlow(pid int) (any, *poolLocal) {
+	fifo := p.Policy == PoolFIFO
 	// See the comment in pin regarding ordering of the loads.
 	size := runtime_LoadAcquintptr(&p.localSize) // load-acquire
 	locals := p.local                            // load-consume
-	// Try to steal one element from other procs.
-	for i := 0; i < int(size); i++ {
+	// Try to steal one element from other procs. pid's own shard
+	// comes last.
+	n := int(size)
+	if fifo {
+		n--
+	}
+	for i := 0; i < n; i++ {
 		l := indexLocal(locals, (pid+i+1)%int(size))
 		if x, _ := l.shared.popTail(); x != nil {
-			return x
+			if i < int(size)-1 { // not pid's own shard
+				indexLocal(locals, pid).countSteal()
+			}
+			return x, l
 		}
 	}
+	if fifo {
+		return nil, nil
+	}
 
-	// Try the victim cache. We do this after attempting to steal
+	// Try the victim caches. We do this after attempting to steal
 	// from all primary caches because we want objects in the
-	// victim cache to age out if at all possible.
-	size = atomic.LoadUintptr(&p.victimSize)
-	if uintptr(pid) >= size {
-		return nil
+	// victim caches to age out if at all possible.
+	x, l := p.getVictims(pid, false)
+	if x != nil {
+		indexLocal(locals, pid).countVictimHit()
+	}
+	return x, l
+}
+
+// getVictims takes an item from the victim caches, trying the
+// generations from youngest to oldest, or from oldest to youngest if
+// oldestFirst is set, and returns it and the poolLocal it was taken from.
+func (p *Pool) getVictims(pid int, oldestFirst bool) (any, *poolLocal) {
+	if !oldestFirst {
+		if x, l := getVictim(&p.victim, &p.victimSize, pid); x != nil {
+			return x, l
+		}
+	}
+	for i := range p.olderVictims {
+		if oldestFirst {
+			i = len(p.olderVictims) - 1 - i
+		}
+		v := &p.olderVictims[i]
+		if x, l := getVictim(&v.local, &v.size, pid); x != nil {
+			return x, l
+		}
+	}
+	if oldestFirst {
+		return getVictim(&p.victim, &p.victimSize, pid)
+	}
+	return nil, nil
+}
+
+// getVictim tries to take an item from the victim cache *local of size
+// *size for the P pid, and returns it and the poolLocal it was taken from.
+func getVictim(local *unsafe.Pointer, size *uintptr, pid int) (any, *poolLocal) {
+	n := atomic.LoadUintptr(size)
+	if uintptr(pid) >= n {
+		return nil, nil
 	}
-	locals = p.victim
+	locals := *local
 	l := indexLocal(locals, pid)
 	if x := l.private; x != nil {
 		l.private = nil
-		return x
+		return x, l
 	}
-	for i := 0; i < int(size); i++ {
-		l := indexLocal(locals, (pid+i)%int(size))
+	for i := 0; i < int(n); i++ {
+		l := indexLocal(locals, (pid+i)%int(n))
 		if x, _ := l.shared.popTail(); x != nil {
-			return x
+			return x, l
 		}
 	}
 
 	// Mark the victim cache as empty for future gets don't bother
 	// with it.
-	atomic.StoreUintptr(&p.victimSize, 0)
+	atomic.StoreUintptr(size, 0)
 
-	return nil
+	return nil, nil
 }
 
 // 这段代码是Go语言标准库中的sync.Pool（同步池）的实现中的一个方法，用于从一个Pool结构体中获取一个poolLocal对象并将其和当前的goroutine ID绑定。
@@ -297,7 +618,13 @@ func (p *Pool) pinSlow() (*poolLocal, int) {
 	if p.local == nil {
 		allPools = append(allPools, p)
 	}
+	if p.Generations > 1 && p.olderVictims == nil {
+		// Allocated once, before the first store to localSize, so
+		// that getSlow can read olderVictims without a lock.
+		p.olderVictims = make([]poolVictim, p.Generations-1)
+	}
 	// If GOMAXPROCS changes between GCs, we re-allocate the array and lose the old one.
+	p.retireStealCounters()
 	size := runtime.GOMAXPROCS(0)                            // 应该是当前正在运行的线程数量(M),或者说P的数量？
 	local := make([]poolLocal, size)                         // 每个线程一个poolLocal
 	atomic.StorePointer(&p.local, unsafe.Pointer(&local[0])) // store-release   local指向第一个pollLocal的地址
@@ -312,14 +639,41 @@ func poolCleanup() {
 	// Because the world is stopped, no pool user can be in a
 	// pinned section (in effect, this has all Ps pinned).
 
-	// Drop victim caches from all pools.
+	// Age the older victim caches, dropping the oldest ones.
+	var aging *Pool
+	for p := agingPools; p != nil; {
+		next := p.nextAging
+		copy(p.olderVictims[1:], p.olderVictims)
+		p.olderVictims[0] = poolVictim{}
+		p.nextAging, p.aging = nil, false
+		for _, v := range p.olderVictims {
+			if v.local != nil {
+				p.nextAging, p.aging = aging, true
+				aging = p
+				break
+			}
+		}
+		p = next
+	}
+
+	// Drop victim caches from all pools, or keep them as the youngest
+	// older victim caches of pools with more generations.
 	for _, p := range oldPools {
+		if len(p.olderVictims) > 0 {
+			p.olderVictims[0] = poolVictim{p.victim, p.victimSize}
+			if !p.aging {
+				p.nextAging, p.aging = aging, true
+				aging = p
+			}
+		}
 		p.victim = nil
 		p.victimSize = 0
 	}
+	agingPools = aging
 
 	// Move primary cache to victim cache.
 	for _, p := range allPools {
+		p.retireStealCounters()
 		p.victim = p.local
 		p.victimSize = p.localSize
 		p.local = nil
@@ -342,12 +696,63 @@ var (
 	// oldPools is the set of pools that may have non-empty victim
 	// caches. Protected by STW.
 	oldPools []*Pool
+
+	// agingPools is a list, linked through Pool.nextAging, of the
+	// pools that may have non-empty older victim caches. It is linked
+	// through the pools themselves so that poolCleanup need not
+	// allocate. Protected by STW.
+	agingPools *Pool
 )
 
 func init() {
 	runtime_registerPoolCleanup(poolCleanup)
+	runtime_registerPoolStats(poolStats)
+}
+
+// poolStats returns the number of items retained by all pools and
+// their total size as reported by Pool.SizeOf, for runtime/metrics.
+func poolStats() (objects, bytes uint64) {
+	// Pin, as getSlow does, so that poolCleanup cannot run and
+	// oldPools and the victim caches stay put during the walk.
+	// allPools is also modified by pinSlow, so hold allPoolsMu,
+	// which can not be locked while pinned.
+	allPoolsMu.Lock()
+	runtime_procPin()
+	var no, nb int64
+	add := func(locals unsafe.Pointer, size uintptr) {
+		for i := 0; i < int(size); i++ {
+			l := indexLocal(locals, i)
+			no += l.objects.Load()
+			nb += l.bytes.Load()
+		}
+	}
+	for _, p := range allPools {
+		add(p.local, p.localSize) // set under allPoolsMu
+	}
+	for _, p := range oldPools {
+		add(p.victim, atomic.LoadUintptr(&p.victimSize))
+	}
+	for p := agingPools; p != nil; p = p.nextAging {
+		for _, v := range p.olderVictims {
+			add(v.local, atomic.LoadUintptr(&v.size))
+		}
+	}
+	runtime_procUnpin()
+	allPoolsMu.Unlock()
+
+	// Puts and Gets in progress can make the counts briefly negative.
+	if no > 0 {
+		objects = uint64(no)
+	}
+	if nb > 0 {
+		bytes = uint64(nb)
+	}
+	return
 }
 
+// indexLocal returns the i'th element of the [P]poolLocal array at l.
+// Since the size of poolLocal is a multiple of the cache line size,
+// consecutive elements never share a cache line.
 func indexLocal(l unsafe.Pointer, i int) *poolLocal {
 	lp := unsafe.Pointer(uintptr(l) + uintptr(i)*unsafe.Sizeof(poolLocal{}))
 	return (*poolLocal)(lp)
@@ -355,6 +760,8 @@ func indexLocal(l unsafe.Pointer, i int) *poolLocal {
 
 // Implemented in runtime.
 func runtime_registerPoolCleanup(cleanup func()) // 注册一个池清理函数，当需要清理池时，该函数会被调用。它可以用于在内存使用量较高时清理池，以避免内存泄漏
+func runtime_registerPoolStats(stats func() (objects, bytes uint64))
+
 // 该函数可能会将当前 goroutine 绑定到 OS 线程上，以确保在此期间禁用抢占机制
 func runtime_procPin() int // 用于将当前的 goroutine 固定在其所在的处理器上。如果没有可用的处理器，则该方法会阻塞等待，直到有处理器可用。它可以用于确保某个 goroutine 只在指定的处理器上运行；该函数用于将当前 goroutine（协程）绑定到特定的处理器上。如果调用成功，则返回处理器的 ID
 func runtime_procUnpin()   // 用于取消当前 goroutine 在处理器上的固定。如果当前 goroutine 没有固定任何处理器，则该方法不会产生任何影响
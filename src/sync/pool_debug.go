@@ -0,0 +1,124 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build pooldebug
+
+package sync
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// Contains reports whether x is currently held by p, comparing by
+// identity: x must have the same dynamic type as a pooled item and,
+// for pointer-shaped types, point to the same object. It is intended
+// for finding items that are Put twice, by checking in tests that an
+// item is not already in the Pool before it is Put.
+//
+// Contains scans every per-P cache and victim cache of p, so it takes
+// time proportional to the number of pooled items. It may be called
+// concurrently with Get and Put, in which case the result may be out of
+// date by the time it is returned.
+//
+// Contains is only available when building with the pooldebug build tag.
+func (p *Pool) Contains(x any) bool {
+	if x == nil {
+		return false
+	}
+	// Pin so that poolCleanup cannot run while we scan the caches.
+	runtime_procPin()
+	defer runtime_procUnpin()
+
+	e := *(*eface)(unsafe.Pointer(&x))
+	size := runtime_LoadAcquintptr(&p.localSize) // load-acquire
+	locals := p.local                            // load-consume
+	if localsContain(locals, size, e) {
+		return true
+	}
+	if localsContain(p.victim, atomic.LoadUintptr(&p.victimSize), e) {
+		return true
+	}
+	for _, v := range p.olderVictims {
+		if localsContain(v.local, atomic.LoadUintptr(&v.size), e) {
+			return true
+		}
+	}
+	return false
+}
+
+// localsContain reports whether the per-P caches locals, of size size,
+// hold an item with the interface words e.
+func localsContain(locals unsafe.Pointer, size uintptr, e eface) bool {
+	for i := 0; i < int(size); i++ {
+		l := indexLocal(locals, i)
+		if l.private != nil && *(*eface)(unsafe.Pointer(&l.private)) == e {
+			return true
+		}
+		for d := loadPoolChainElt(&l.shared.tail); d != nil; d = loadPoolChainElt(&d.next) {
+			for j := range d.vals {
+				slot := &d.vals[j]
+				if atomic.LoadPointer(&slot.typ) == e.typ && slot.val == e.val {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// StealStats reports how many times Get has taken an item from another
+// P's cache, and how many times it has taken one from the victim caches,
+// which hold items that have survived a garbage collection. A high
+// number of steals relative to the number of Gets means that items are
+// put on one P and got on another, for example because producers and
+// consumers run on different goroutines; a high number of victim hits
+// means that the Pool's primary caches are often empty. Both counts
+// cover the whole life of p.
+//
+// StealStats is only available when building with the pooldebug build
+// tag.
+func (p *Pool) StealStats() (steals, victimHits uint64) {
+	runtime_procPin()
+	defer runtime_procUnpin()
+
+	steals, victimHits = p.retiredSteals.Load(), p.retiredVictimHits.Load()
+	size := runtime_LoadAcquintptr(&p.localSize) // load-acquire
+	locals := p.local                            // load-consume
+	for i := 0; i < int(size); i++ {
+		l := indexLocal(locals, i)
+		steals += l.steals.Load()
+		victimHits += l.victimHits.Load()
+	}
+	return steals, victimHits
+}
+
+// poolStealCounters counts the items that Get took on one P from other
+// Ps' caches and from the victim caches. It is embedded in
+// poolLocalInternal, so that Ps do not contend for the counters.
+type poolStealCounters struct {
+	steals     atomic.Uint64
+	victimHits atomic.Uint64
+}
+
+func (c *poolStealCounters) countSteal()     { c.steals.Add(1) }
+func (c *poolStealCounters) countVictimHit() { c.victimHits.Add(1) }
+
+// poolStealTotals holds the counts of a Pool's primary caches that have
+// been retired to the victim caches. It is embedded in Pool.
+type poolStealTotals struct {
+	retiredSteals     atomic.Uint64
+	retiredVictimHits atomic.Uint64
+}
+
+// retireStealCounters adds the counts of p's primary caches to its
+// totals before poolCleanup retires the caches. It runs with the world
+// stopped.
+func (p *Pool) retireStealCounters() {
+	for i := 0; i < int(p.localSize); i++ {
+		l := indexLocal(p.local, i)
+		p.retiredSteals.Add(l.steals.Load())
+		p.retiredVictimHits.Add(l.victimHits.Load())
+	}
+}
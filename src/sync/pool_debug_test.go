@@ -0,0 +1,87 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build pooldebug && !race
+
+package sync_test
+
+import (
+	"runtime"
+	"std/runtime/debug"
+	. "sync"
+	"testing"
+)
+
+func TestPoolContains(t *testing.T) {
+	// Disable GC so items are not dropped from the pool.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	var p Pool
+	a, b := new(int), new(int)
+	if p.Contains(a) {
+		t.Fatal("empty pool contains a")
+	}
+	p.Put(a)
+	if !p.Contains(a) {
+		t.Fatal("pool does not contain a after Put")
+	}
+	if p.Contains(b) {
+		t.Fatal("pool contains b, which was never Put")
+	}
+
+	// Fill the pool so that items spill over into the shared chain.
+	items := make([]*int, 100)
+	for i := range items {
+		items[i] = new(int)
+		p.Put(items[i])
+	}
+	for i, x := range items {
+		if !p.Contains(x) {
+			t.Fatalf("pool does not contain items[%d]", i)
+		}
+	}
+
+	// Items move to the victim cache on the first GC.
+	runtime.GC()
+	if !p.Contains(a) {
+		t.Fatal("pool does not contain a after one GC")
+	}
+	runtime.GC()
+	if p.Contains(a) {
+		t.Fatal("pool contains a after two GCs")
+	}
+}
+
+func TestPoolStealStats(t *testing.T) {
+	// Disable GC so items move to the victim cache only when we say so.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	var p Pool
+	if steals, victimHits := p.StealStats(); steals != 0 || victimHits != 0 {
+		t.Fatalf("empty pool: StealStats() = %d, %d; want 0, 0", steals, victimHits)
+	}
+	p.Put(new(int))
+	if p.Get() == nil {
+		t.Fatal("Get returned nil after Put")
+	}
+	if _, victimHits := p.StealStats(); victimHits != 0 {
+		t.Fatalf("Get from primary cache: victimHits = %d; want 0", victimHits)
+	}
+
+	p.Put(new(int))
+	runtime.GC()
+	if p.Get() == nil {
+		t.Fatal("Get returned nil after Put and one GC")
+	}
+	if _, victimHits := p.StealStats(); victimHits != 1 {
+		t.Fatalf("Get from victim cache: victimHits = %d; want 1", victimHits)
+	}
+
+	// The counts survive the primary caches being retired.
+	runtime.GC()
+	runtime.GC()
+	if _, victimHits := p.StealStats(); victimHits != 1 {
+		t.Fatalf("after two GCs: victimHits = %d; want 1", victimHits)
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !pooldebug
+
+package sync
+
+// Without the pooldebug build tag, Get does not count steals and victim
+// hits, and Pool has no StealStats method.
+
+type poolStealCounters struct{}
+
+func (*poolStealCounters) countSteal()     {}
+func (*poolStealCounters) countVictimHit() {}
+
+type poolStealTotals struct{}
+
+func (*Pool) retireStealCounters() {}
@@ -9,9 +9,12 @@
 package sync_test
 
 import (
+	"std/context"
 	"std/runtime"
 	"std/runtime/debug"
+	"std/runtime/metrics"
 	"std/sort"
+	"std/strings"
 	. "std/sync"
 	"std/sync/atomic"
 	"std/time"
@@ -67,6 +70,215 @@ func TestPool(t *testing.T) {
 	}
 }
 
+func TestPoolFIFO(t *testing.T) {
+	// disable GC so we can control when it happens.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	p := Pool{Policy: PoolFIFO}
+
+	// Make sure that the goroutine doesn't migrate to another P
+	// between Put and Get calls.
+	Runtime_procPin()
+	for _, s := range []string{"a", "b", "c"} {
+		p.Put(s)
+	}
+	p.Put("d")
+	for _, want := range []string{"a", "b"} {
+		if g := p.Get(); g != want {
+			t.Fatalf("got %#v; want %s", g, want)
+		}
+	}
+	p.Put("e")
+	for _, want := range []string{"c", "d", "e"} {
+		if g := p.Get(); g != want {
+			t.Fatalf("got %#v; want %s", g, want)
+		}
+	}
+	if g := p.Get(); g != nil {
+		t.Fatalf("got %#v; want nil", g)
+	}
+	Runtime_procUnpin()
+
+	// Items that survived a GC are older than any Put since,
+	// so they come first.
+	p.Put("old")
+	runtime.GC()
+	p.Put("new")
+	if g := p.Get(); g != "old" {
+		t.Fatalf("got %#v; want old after GC", g)
+	}
+	if g := p.Get(); g != "new" {
+		t.Fatalf("got %#v; want new after GC", g)
+	}
+}
+
+func TestPoolGetWait(t *testing.T) {
+	var created atomic.Int32
+	p := Pool{
+		New: func() any {
+			return created.Add(1)
+		},
+		MaxActive: 2,
+	}
+	ctx := context.Background()
+	a, err := p.GetWait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetWait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A third GetWait blocks until an item is Put, and gets that item.
+	got := make(chan any)
+	go func() {
+		x, err := p.GetWait(ctx)
+		if err != nil {
+			t.Error(err)
+		}
+		got <- x
+	}()
+	select {
+	case x := <-got:
+		t.Fatalf("GetWait returned %v with MaxActive items out", x)
+	case <-time.After(10 * time.Millisecond):
+	}
+	p.Put(a)
+	if x := <-got; x != a {
+		t.Errorf("blocked GetWait = %v, want the item Put (%v)", x, a)
+	}
+	if n := created.Load(); n != 2 {
+		t.Errorf("New called %d times, want 2", n)
+	}
+
+	// A canceled GetWait gives up.
+	canceled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if x, err := p.GetWait(canceled); x != nil || err != context.DeadlineExceeded {
+		t.Errorf("canceled GetWait = %v, %v, want nil, %v", x, err, context.DeadlineExceeded)
+	}
+
+	// Without waiters, Put frees a slot for GetWait.
+	p.Put(a)
+	if _, err := p.GetWait(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoolGetWaitEmpty(t *testing.T) {
+	// Without New, GetWait on an empty Pool hands out nothing, so it
+	// must not use up a slot.
+	p := Pool{MaxActive: 1}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		if x, err := p.GetWait(ctx); x != nil || err != nil {
+			t.Fatalf("GetWait #%d on empty Pool = %v, %v, want nil, nil", i, x, err)
+		}
+	}
+}
+
+func TestPoolGetWaitEmptyWithWaiter(t *testing.T) {
+	// If the holder of the last slot gets nothing, the slot passes to
+	// a GetWait call that was already waiting.
+	release := make(chan bool)
+	var calls atomic.Int32
+	p := Pool{
+		New: func() any {
+			if calls.Add(1) == 1 {
+				<-release
+				return nil
+			}
+			return "b"
+		},
+		MaxActive: 1,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	holder := make(chan any)
+	go func() {
+		x, _ := p.GetWait(ctx)
+		holder <- x
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond) // until the holder has the slot
+	}
+	waiter := make(chan any)
+	go func() {
+		x, err := p.GetWait(ctx)
+		if err != nil {
+			t.Error(err)
+		}
+		waiter <- x
+	}()
+	time.Sleep(10 * time.Millisecond) // let the waiter queue up
+	close(release)
+	if x := <-holder; x != nil {
+		t.Errorf("holder GetWait = %v, want nil", x)
+	}
+	if x := <-waiter; x != "b" {
+		t.Errorf("waiting GetWait = %v, want b", x)
+	}
+}
+
+func TestPoolGenerations(t *testing.T) {
+	// disable GC so we can control when it happens.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	p := Pool{Generations: 3}
+	for i := 0; i < 100; i++ {
+		p.Put("c")
+	}
+	for gc := 1; gc <= p.Generations; gc++ {
+		runtime.GC()
+		if g := p.Get(); g != "c" {
+			t.Fatalf("got %#v; want c after %d GCs", g, gc)
+		}
+	}
+	runtime.GC()
+	if g := p.Get(); g != nil {
+		t.Fatalf("got %#v; want nil after %d GCs", g, p.Generations+1)
+	}
+}
+
+func TestPoolRetainedMetrics(t *testing.T) {
+	// disable GC so we can control when it happens.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	samples := []metrics.Sample{
+		{Name: "/sync/pool/retained-objects:objects"},
+		{Name: "/sync/pool/retained-bytes:bytes"},
+	}
+	read := func() (objects, bytes uint64) {
+		metrics.Read(samples)
+		return samples[0].Value.Uint64(), samples[1].Value.Uint64()
+	}
+	check := func(when string, baseObjects, baseBytes, wantObjects, wantBytes uint64) {
+		t.Helper()
+		objects, bytes := read()
+		if objects-baseObjects != wantObjects || bytes-baseBytes != wantBytes {
+			t.Errorf("%s: retained %d objects and %d bytes, want %d and %d",
+				when, objects-baseObjects, bytes-baseBytes, wantObjects, wantBytes)
+		}
+	}
+
+	runtime.GC()
+	runtime.GC()
+	baseObjects, baseBytes := read()
+	p := Pool{SizeOf: func(x any) uintptr { return uintptr(cap(x.([]byte))) }}
+	var q Pool // contributes objects only
+	for i := 0; i < 10; i++ {
+		p.Put(make([]byte, 100))
+		q.Put(new(int))
+	}
+	check("after Put", baseObjects, baseBytes, 20, 1000)
+	for i := 0; i < 4; i++ {
+		p.Get()
+	}
+	check("after Get", baseObjects, baseBytes, 16, 600)
+	runtime.GC()
+	check("after one GC", baseObjects, baseBytes, 16, 600)
+	runtime.GC()
+	check("after two GCs", baseObjects, baseBytes, 0, 0)
+}
+
 func TestPoolNew(t *testing.T) {
 	// disable GC so we can control when it happens.
 	defer debug.SetGCPercent(debug.SetGCPercent(-1))
@@ -99,6 +311,36 @@ func TestPoolNew(t *testing.T) {
 	}
 }
 
+func TestPoolGetFresh(t *testing.T) {
+	// disable GC so we can control when it happens.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	var p Pool
+	if v, fresh := p.GetFresh(); v != nil || fresh {
+		t.Fatalf("got %v, %v; want nil, false", v, fresh)
+	}
+	p.New = func() any { return 1 }
+	if v, fresh := p.GetFresh(); v != 1 || !fresh {
+		t.Fatalf("got %v, %v; want 1, true", v, fresh)
+	}
+
+	// Make sure that the goroutine doesn't migrate to another P
+	// between Put and Get calls.
+	Runtime_procPin()
+	p.Put(42)
+	v, fresh := p.GetFresh()
+	Runtime_procUnpin()
+	if v != 42 || fresh {
+		t.Fatalf("got %v, %v; want 42, false", v, fresh)
+	}
+}
+
+func TestPoolLocalPadding(t *testing.T) {
+	if PoolLocalSize%CacheLinePadSize != 0 {
+		t.Errorf("poolLocal size %d is not a multiple of the cache line size %d", PoolLocalSize, CacheLinePadSize)
+	}
+}
+
 // Test that Pool does not hold pointers to previously cached resources.
 func TestPoolGC(t *testing.T) {
 	testPool(t, true)
@@ -142,6 +384,55 @@ loop:
 	}
 }
 
+func TestPoolFinalizer(t *testing.T) {
+	var fin uint32
+	p := Pool{Finalizer: func(x any) {
+		atomic.AddUint32(&fin, 1)
+	}}
+	const N = 100
+	for i := 0; i < N; i++ {
+		p.Put(new(string))
+	}
+	// Items taken back out of the pool must not be finalized.
+	const kept = 10
+	for i := 0; i < kept; i++ {
+		if p.Get() == nil {
+			break
+		}
+	}
+	// Drop the primary and then the victim cache.
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+		time.Sleep(time.Duration(i*100+10) * time.Millisecond)
+		// 1 pointer can remain on stack or elsewhere
+		if atomic.LoadUint32(&fin) >= N-kept-1 {
+			break
+		}
+	}
+	if got := atomic.LoadUint32(&fin); got < N-kept-1 || got > N-kept {
+		t.Fatalf("%v resources finalized, want %v", got, N-kept)
+	}
+}
+
+func TestPoolFinalizerBadItem(t *testing.T) {
+	p := Pool{Finalizer: func(x any) {}}
+	withFinalizer := new(string)
+	runtime.SetFinalizer(withFinalizer, func(*string) {})
+	defer runtime.SetFinalizer(withFinalizer, nil)
+	type big struct{ a, b [4]int64 }
+	for _, x := range []any{1, "s", &new(big).b, withFinalizer} {
+		func() {
+			defer func() {
+				r, _ := recover().(string)
+				if !strings.HasPrefix(r, "sync: Pool.Put with Finalizer set: ") {
+					t.Errorf("Put(%T) panicked with %q, want a sync panic", x, r)
+				}
+			}()
+			p.Put(x)
+		}()
+	}
+}
+
 func TestPoolStress(t *testing.T) {
 	const P = 10
 	N := int(1e6)
@@ -172,6 +463,43 @@ func TestPoolStress(t *testing.T) {
 	}
 }
 
+func TestSizedPool(t *testing.T) {
+	// disable GC so we can control when it happens.
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	var p SizedPool
+	for _, size := range []int{0, 1, 64, 65, 1000, 4096, 1 << 20, 1<<20 + 1} {
+		b := p.Get(size)
+		if len(b) != size || cap(b) < size {
+			t.Fatalf("Get(%d): len=%d cap=%d", size, len(b), cap(b))
+		}
+		p.Put(b)
+	}
+
+	// Make sure that the goroutine doesn't migrate to another P
+	// between Put and Get calls.
+	var q SizedPool
+	b := make([]byte, 100)
+	Runtime_procPin()
+	q.Put(b)
+	// A 100-byte buffer only satisfies the 64-byte class.
+	g1 := q.Get(100)
+	g2 := q.Get(50)
+	Runtime_procUnpin()
+	if &g1[0] == &b[0] {
+		t.Fatalf("Get(100) returned a buffer from a smaller size class")
+	}
+	if &g2[0] != &b[0] {
+		t.Fatalf("Get(50) did not reuse the buffer put in the 64-byte class")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Put(p.Get(1000))
+	})
+	if allocs != 0 {
+		t.Errorf("Get/Put round trip allocated %v times, want 0", allocs)
+	}
+}
+
 func TestPoolDequeue(t *testing.T) {
 	testPoolDequeue(t, NewPoolDequeue(16))
 }
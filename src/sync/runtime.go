@@ -82,3 +82,10 @@ func runtime_canSpin(i int) bool
 func runtime_doSpin()
 
 func runtime_nanotime() int64
+
+// runtime_sleep pauses the current goroutine for at least ns nanoseconds.
+func runtime_sleep(ns int64)
+
+// runtime_checkFinalizer returns why runtime.SetFinalizer would reject x
+// as the object of a finalizer, or "" if it would accept it.
+func runtime_checkFinalizer(x any) string
@@ -0,0 +1,90 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+// Size classes used by SizedPool. Class i holds buffers whose
+// capacity is at least 1<<(sizedPoolMinShift+i) bytes.
+const (
+	sizedPoolMinShift = 6  // 64 B
+	sizedPoolMaxShift = 20 // 1 MiB
+	sizedPoolClasses  = sizedPoolMaxShift - sizedPoolMinShift + 1
+)
+
+// A SizedPool is a set of byte-slice Pools segregated by size class.
+//
+// Requested sizes are rounded up to the next power of two between 64 bytes
+// and 1 MiB, and each size class is served by its own Pool, so a buffer
+// returned by Get is never smaller than requested. Requests larger than the
+// largest size class are allocated directly and are not retained by Put.
+//
+// The zero SizedPool is empty and ready for use.
+// A SizedPool must not be copied after first use.
+type SizedPool struct {
+	// pools hold *[]byte rather than []byte, since storing a slice
+	// in an interface allocates. The *[]byte of a buffer returned
+	// by Get is kept in boxes, emptied, for the next Put.
+	pools [sizedPoolClasses]Pool
+	boxes Pool
+}
+
+// sizedPoolClass returns the index of the smallest size class that
+// can hold n bytes, or -1 if n exceeds the largest size class.
+func sizedPoolClass(n int) int {
+	if n <= 1<<sizedPoolMinShift {
+		return 0
+	}
+	if n > 1<<sizedPoolMaxShift {
+		return -1
+	}
+	shift := sizedPoolMinShift
+	for 1<<shift < n {
+		shift++
+	}
+	return shift - sizedPoolMinShift
+}
+
+// Get returns a byte slice of length size whose capacity is at least size.
+// The contents of the returned slice are unspecified.
+// Get panics if size is negative.
+func (p *SizedPool) Get(size int) []byte {
+	if size < 0 {
+		panic("sync: negative SizedPool.Get size")
+	}
+	c := sizedPoolClass(size)
+	if c < 0 {
+		return make([]byte, size)
+	}
+	if x := p.pools[c].Get(); x != nil {
+		bp := x.(*[]byte)
+		buf := *bp
+		*bp = nil
+		p.boxes.Put(bp)
+		return buf[:size]
+	}
+	return make([]byte, size, 1<<(sizedPoolMinShift+c))
+}
+
+// Put adds buf to the pool for the largest size class that its capacity
+// can satisfy. Buffers smaller than the smallest size class or larger
+// than the largest one are dropped.
+// The caller must not use buf after calling Put.
+func (p *SizedPool) Put(buf []byte) {
+	n := cap(buf)
+	if n < 1<<sizedPoolMinShift || n > 1<<sizedPoolMaxShift {
+		return
+	}
+	c := sizedPoolClass(n)
+	if 1<<(sizedPoolMinShift+c) > n {
+		// Round down so that every buffer in class c can satisfy
+		// a request for 1<<(sizedPoolMinShift+c) bytes.
+		c--
+	}
+	bp, _ := p.boxes.Get().(*[]byte)
+	if bp == nil {
+		bp = new([]byte)
+	}
+	*bp = buf[:0]
+	p.pools[c].Put(bp)
+}
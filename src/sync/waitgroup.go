@@ -163,3 +163,38 @@ func (wg *WaitGroup) Wait() {
 		}
 	}
 }
+
+// waitProgressPoll is the longest WaitProgress sleeps between checks
+// of the counter, in nanoseconds, so that it returns promptly once the
+// counter reaches zero even if the reporting interval is long.
+const waitProgressPoll = 10e6
+
+// WaitProgress is like Wait but, while the WaitGroup counter is not
+// zero, calls report with the counter immediately and then after each
+// interval elapses. It does not call report once the counter has
+// reached zero. The counter is read by polling, so report may miss
+// intermediate values, and WaitProgress may return up to 10ms after
+// Wait would have. If interval is not positive, WaitProgress is
+// equivalent to Wait.
+func (wg *WaitGroup) WaitProgress(interval interface{ Nanoseconds() int64 }, report func(remaining int)) {
+	if ns := interval.Nanoseconds(); ns > 0 {
+		statep, _ := wg.state()
+		next := runtime_nanotime()
+		for {
+			n := int32(atomic.LoadUint64(statep) >> 32)
+			if n == 0 {
+				break
+			}
+			if now := runtime_nanotime(); now >= next {
+				report(int(n))
+				next = now + ns
+			}
+			sleep := next - runtime_nanotime()
+			if sleep > waitProgressPoll {
+				sleep = waitProgressPoll
+			}
+			runtime_sleep(sleep)
+		}
+	}
+	wg.Wait()
+}
@@ -5,9 +5,11 @@
 package sync_test
 
 import (
+	"runtime"
 	. "sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func testWaitGroup(t *testing.T, wg1 *WaitGroup, wg2 *WaitGroup) {
@@ -98,6 +100,77 @@ func TestWaitGroupAlign(t *testing.T) {
 	x.wg.Wait()
 }
 
+func TestWaitGroupWaitProgress(t *testing.T) {
+	var wg WaitGroup
+	const n = 3
+	wg.Add(n)
+	release := make(chan struct{})
+	for i := 0; i < n; i++ {
+		go func() {
+			<-release
+			time.Sleep(5 * time.Millisecond)
+			wg.Done()
+		}()
+	}
+	var reports []int
+	wg.WaitProgress(time.Millisecond, func(remaining int) {
+		reports = append(reports, remaining)
+		if len(reports) == 1 {
+			close(release)
+		}
+	})
+	if len(reports) == 0 || reports[0] != n {
+		t.Fatalf("reports = %v, want first report %d", reports, n)
+	}
+	for i, r := range reports {
+		if r <= 0 || r > n || (i > 0 && r > reports[i-1]) {
+			t.Errorf("reports = %v, want non-increasing values in [1, %d]", reports, n)
+			break
+		}
+	}
+
+	// With no outstanding work, report is never called.
+	wg.WaitProgress(time.Millisecond, func(int) { t.Errorf("report called with zero counter") })
+}
+
+func TestBoundedGroup(t *testing.T) {
+	const limit, n = 3, 50
+	g := NewBoundedGroup(limit)
+	var running, peak, done int32
+	for i := 0; i < n; i++ {
+		g.Go(func() {
+			r := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if r <= p || atomic.CompareAndSwapInt32(&peak, p, r) {
+					break
+				}
+			}
+			for j := 0; j < 100; j++ {
+				runtime.Gosched()
+			}
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&done, 1)
+		})
+	}
+	g.Wait()
+	if done != n {
+		t.Fatalf("Wait returned after %d of %d functions finished", done, n)
+	}
+	if peak > limit {
+		t.Fatalf("%d functions ran concurrently, want at most %d", peak, limit)
+	}
+}
+
+func TestBoundedGroupNonPositiveLimit(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewBoundedGroup(0) did not panic")
+		}
+	}()
+	NewBoundedGroup(0)
+}
+
 func BenchmarkWaitGroupUncontended(b *testing.B) {
 	type PaddedWaitGroup struct {
 		WaitGroup
@@ -0,0 +1,148 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+// Weighted provides a way to bound concurrent access to a resource.
+// The callers can request access with a given weight.
+//
+// Waiters are served in FIFO order: a waiter that cannot be satisfied
+// blocks all waiters behind it, so that large requests are not starved
+// by a steady stream of small ones.
+//
+// A Weighted must be created with NewWeighted and must not be copied
+// after first use.
+type Weighted struct {
+	size    int64
+	cur     int64
+	mu      Mutex
+	waiters []*weightedWaiter // FIFO queue of blocked Acquire calls
+}
+
+type weightedWaiter struct {
+	n     int64
+	ready chan<- struct{} // closed when the semaphore is acquired
+}
+
+// NewWeighted creates a new weighted semaphore with the given
+// maximum combined weight for concurrent access.
+func NewWeighted(n int64) *Weighted {
+	return &Weighted{size: n}
+}
+
+// Acquire acquires the semaphore with a weight of n, blocking until
+// resources are available or ctx is done. On success, it returns nil.
+// On failure, it returns ctx.Err() and leaves the semaphore unchanged.
+//
+// If ctx is already done, Acquire may still succeed without blocking.
+func (s *Weighted) Acquire(ctx interface {
+	Done() <-chan struct{}
+	Err() error
+}, n int64) error {
+	s.mu.Lock()
+	if s.size-s.cur >= n && len(s.waiters) == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+
+	done := ctx.Done()
+	if n > s.size {
+		// Don't make other Acquire calls block on one that's doomed to fail.
+		s.mu.Unlock()
+		<-done
+		return ctx.Err()
+	}
+
+	ready := make(chan struct{})
+	w := &weightedWaiter{n: n, ready: ready}
+	s.waiters = append(s.waiters, w)
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+		err := ctx.Err()
+		s.mu.Lock()
+		select {
+		case <-ready:
+			// Acquired the semaphore after we were canceled.
+			// Rather than trying to fix up the queue, just pretend
+			// we didn't notice the cancellation.
+			err = nil
+		default:
+			isFront := s.waiters[0] == w
+			s.removeWaiter(w)
+			// If we're at the front and there're extra tokens left,
+			// notify other waiters.
+			if isFront && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+		s.mu.Unlock()
+		return err
+
+	case <-ready:
+		return nil
+	}
+}
+
+// TryAcquire acquires the semaphore with a weight of n without
+// blocking. On success, it returns true. On failure, it returns false
+// and leaves the semaphore unchanged.
+func (s *Weighted) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	success := s.size-s.cur >= n && len(s.waiters) == 0
+	if success {
+		s.cur += n
+	}
+	s.mu.Unlock()
+	return success
+}
+
+// Release releases the semaphore with a weight of n.
+// It panics if more is released than is held.
+func (s *Weighted) Release(n int64) {
+	s.mu.Lock()
+	s.cur -= n
+	if s.cur < 0 {
+		s.mu.Unlock()
+		panic("sync: Weighted released more than held")
+	}
+	s.notifyWaiters()
+	s.mu.Unlock()
+}
+
+// removeWaiter removes w from the queue of waiters.
+// s.mu must be held.
+func (s *Weighted) removeWaiter(w *weightedWaiter) {
+	for i, x := range s.waiters {
+		if x == w {
+			copy(s.waiters[i:], s.waiters[i+1:])
+			s.waiters[len(s.waiters)-1] = nil
+			s.waiters = s.waiters[:len(s.waiters)-1]
+			return
+		}
+	}
+}
+
+// notifyWaiters grants the semaphore to waiters at the front of the
+// queue for as long as their requests can be satisfied.
+// s.mu must be held.
+func (s *Weighted) notifyWaiters() {
+	for len(s.waiters) > 0 {
+		w := s.waiters[0]
+		if s.size-s.cur < w.n {
+			// Not enough tokens for the next waiter. We could keep going
+			// (to try to find a waiter with a smaller request), but under
+			// load that could cause starvation for large requests;
+			// instead, we leave all remaining waiters blocked.
+			break
+		}
+
+		s.cur += w.n
+		s.waiters[0] = nil
+		s.waiters = s.waiters[1:]
+		close(w.ready)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sync_test
+
+import (
+	"context"
+	"runtime"
+	. "sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWeighted(t *testing.T) {
+	const size = 4
+	s := NewWeighted(size)
+	var held, peak int64
+	var wg WaitGroup
+	for i := 0; i < 4*runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func(n int64) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := s.Acquire(context.Background(), n); err != nil {
+					t.Error(err)
+					return
+				}
+				h := atomic.AddInt64(&held, n)
+				for {
+					p := atomic.LoadInt64(&peak)
+					if h <= p || atomic.CompareAndSwapInt64(&peak, p, h) {
+						break
+					}
+				}
+				atomic.AddInt64(&held, -n)
+				s.Release(n)
+			}
+		}(int64(i%size + 1))
+	}
+	wg.Wait()
+	if peak > size {
+		t.Fatalf("weight %d held concurrently, want at most %d", peak, size)
+	}
+	if !s.TryAcquire(size) {
+		t.Fatalf("TryAcquire(%d) failed after all weight was released", size)
+	}
+	if s.TryAcquire(1) {
+		t.Fatalf("TryAcquire(1) succeeded with no weight available")
+	}
+	s.Release(size)
+}
+
+func TestWeightedAcquireCanceled(t *testing.T) {
+	s := NewWeighted(2)
+	if err := s.Acquire(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Acquire(ctx, 1); err != context.DeadlineExceeded {
+		t.Fatalf("Acquire with expired context = %v, want %v", err, context.DeadlineExceeded)
+	}
+	// Requests larger than the semaphore fail only when ctx is done.
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := s.Acquire(ctx, 3); err != context.Canceled {
+		t.Fatalf("Acquire of more than the size = %v, want %v", err, context.Canceled)
+	}
+	s.Release(2)
+	if !s.TryAcquire(2) {
+		t.Fatalf("canceled Acquire left the semaphore changed")
+	}
+}
+
+func TestWeightedFIFO(t *testing.T) {
+	s := NewWeighted(2)
+	if err := s.Acquire(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	acquired := make(chan struct{})
+	go func() {
+		s.Acquire(context.Background(), 2)
+		close(acquired)
+	}()
+	// Once the large request is queued, smaller ones that arrive after
+	// it must wait even though one unit is free.
+	for s.TryAcquire(1) {
+		s.Release(1)
+		runtime.Gosched()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Acquire(ctx, 1); err != context.Canceled {
+		t.Fatalf("Acquire behind a queued waiter = %v, want %v", err, context.Canceled)
+	}
+	s.Release(1)
+	<-acquired
+	s.Release(2)
+}
+
+func TestWeightedReleasePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Release of more than held did not panic")
+		}
+	}()
+	NewWeighted(1).Release(1)
+}